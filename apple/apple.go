@@ -0,0 +1,97 @@
+package apple
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Issuer is the issuer of apple id tokens.
+const Issuer string = "https://appleid.apple.com"
+
+// KeysURL is the JSON Web Key Set used by apple to sign id tokens.
+const KeysURL string = "https://appleid.apple.com/auth/keys"
+
+var defaultKeySet oidc.KeySet = oidc.NewRemoteKeySet(KeysURL, nil)
+
+// User represents the identity of an apple account found in the id token.
+//
+// https://developer.apple.com/documentation/sign_in_with_apple/sign_in_with_apple_rest_api/authenticating_users_with_sign_in_with_apple
+type User struct {
+	// ID is the stable unique identifier of the user (sub claim).
+	ID            string
+	Email         string
+	EmailVerified bool
+	// IsPrivateEmail indicates if the email is a private relay email.
+	IsPrivateEmail bool
+	// RealUserStatus indicates the likelihood of the user being real: 0 (unsupported),
+	// 1 (unknown), 2 (likely real).
+	RealUserStatus int
+}
+
+// Identity implements autho.Identifier.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:            u.ID,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+	}
+}
+
+type claims struct {
+	Subject        string     `json:"sub"`
+	Email          string     `json:"email"`
+	EmailVerified  boolString `json:"email_verified"`
+	IsPrivateEmail boolString `json:"is_private_email"`
+	RealUserStatus int        `json:"real_user_status"`
+}
+
+// boolString represents a boolean claim which apple sends either as a boolean or as a string.
+type boolString bool
+
+func (b *boolString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		*b = boolString(v)
+		return nil
+	}
+
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = boolString(v)
+	return nil
+}
+
+func newVerifier(cfg *oauth2.Config, o *options) *oidc.Verifier {
+	return oidc.NewVerifier(o.keySet, &oidc.Config{
+		ClientIDs: append([]string{cfg.ClientID}, o.audiences...),
+		Issuers:   []string{Issuer},
+	})
+}
+
+func userFromIDToken(tkn *oidc.IDToken) (*User, error) {
+	var c claims
+	if err := tkn.Claims(&c); err != nil {
+		return nil, err
+	}
+	if c.Subject == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return &User{
+		ID:             c.Subject,
+		Email:          c.Email,
+		EmailVerified:  bool(c.EmailVerified),
+		IsPrivateEmail: bool(c.IsPrivateEmail),
+		RealUserStatus: c.RealUserStatus,
+	}, nil
+}
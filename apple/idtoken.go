@@ -0,0 +1,55 @@
+package apple
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+// NewIDTokenHandler creates a new handler which signs in users with an apple identity token
+// obtained by the native AuthenticationServices framework and posted under the "id_token" form
+// value. The id token signature, audience and issuer are verified before the user resource is
+// set under the request context, calling on success the terminalHandler.
+//
+// The accepted audience is cfg.ClientID (your services id), use apple.WithAudiences to accept
+// the bundle id of your app.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*apple.User)
+func NewIDTokenHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	verifier := newVerifier(cfg, newOptions(opts))
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			autho.PassError(errors.New("autho: id token must be posted."), errHandler, w, r)
+			return
+		}
+
+		raw := r.PostFormValue("id_token")
+		if raw == "" {
+			autho.PassError(errors.New("autho: id token missing."), errHandler, w, r)
+			return
+		}
+
+		tkn, err := verifier.Verify(r.Context(), raw)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		user, err := userFromIDToken(tkn)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		userCtx := autho.ContextWithUser(r.Context(), user)
+		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
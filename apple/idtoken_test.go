@@ -0,0 +1,73 @@
+package apple
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/oidctest"
+	"golang.org/x/oauth2"
+)
+
+func TestIDTokenHandler(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "com.example.web"}
+
+	var gotUser *User
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+	})
+	h := NewIDTokenHandler(cfg, nil, terminal, WithKeySet(signer.KeySet()), WithAudiences("com.example.app"))
+
+	idToken := signer.Sign(map[string]interface{}{
+		"iss":              Issuer,
+		"sub":              "001234.abcd",
+		"aud":              "com.example.app",
+		"exp":              time.Now().Add(time.Hour).Unix(),
+		"email":            "abc@privaterelay.appleid.com",
+		"email_verified":   "true",
+		"is_private_email": true,
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"id_token": {idToken}}))
+
+	if gotUser == nil {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser.ID != "001234.abcd" || !gotUser.EmailVerified || !gotUser.IsPrivateEmail {
+		t.Fatalf("unexpected user: %+v", gotUser)
+	}
+}
+
+func TestIDTokenHandlerRejectsIssuer(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "com.example.web"}
+
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("terminal handler called with foreign id token")
+	})
+	h := NewIDTokenHandler(cfg, nil, terminal, WithKeySet(signer.KeySet()))
+
+	idToken := signer.Sign(map[string]interface{}{
+		"iss": "https://accounts.google.com",
+		"sub": "001234.abcd",
+		"aud": "com.example.web",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"id_token": {idToken}}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status code 400 but got %d", w.Code)
+	}
+}
+
+func postForm(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
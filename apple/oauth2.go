@@ -0,0 +1,162 @@
+package apple
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is the apple oauth2 endpoint.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://appleid.apple.com/auth/authorize",
+	TokenURL:  "https://appleid.apple.com/auth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// Name represents the name of the user, apple only sends it on the first sign in of the user.
+type Name struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type nameKey struct{}
+
+// NameFromContext harvests the name of the user from the request context, it is only set on
+// the first sign in of the user so persist it.
+func NameFromContext(ctx context.Context) (*Name, bool) {
+	name, ok := ctx.Value(nameKey{}).(*Name)
+	return name, ok
+}
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler apple.NewTokenHandler()
+// wrapped around the default apple.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+		opts...,
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+//
+// When scopes (name, email) are requested apple posts the callback (response_mode=form_post),
+// a cross site post on which browsers only send cookies with SameSite=None and Secure. The
+// state cookie is then set with http.SameSiteNoneMode and Secure unless ckCfg.SameSite is set.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	var opts []oauth2.AuthCodeOption
+	if len(cfg.Scopes) > 0 {
+		opts = append(opts, oauth2.SetAuthURLParam("response_mode", "form_post"))
+
+		if ckCfg.SameSite == 0 {
+			crossSite := *ckCfg
+			crossSite.SameSite = http.SameSiteNoneMode
+			crossSite.Secure = true
+			ckCfg = &crossSite
+		}
+	}
+
+	return autho2.NewLoginHandler(cfg, ckCfg, opts...)
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+//
+// The client secret is generated by apple.WithClientSecret() when set. The name of the user
+// posted on its first sign in is set under the request context, see apple.NameFromContext().
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	o := newOptions(opts)
+
+	var exchangeOpts func(r *http.Request) ([]oauth2.AuthCodeOption, error)
+	if o.clientSecret != nil {
+		// the generated client secret is sent as a parameter of the exchange request.
+		secretCfg := *cfg
+		secretCfg.ClientSecret = ""
+		secretCfg.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+		cfg = &secretCfg
+
+		exchangeOpts = func(r *http.Request) ([]oauth2.AuthCodeOption, error) {
+			secret, err := o.clientSecret.Secret()
+			if err != nil {
+				return nil, err
+			}
+
+			return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("client_secret", secret)}, nil
+		}
+	}
+	tokenHandler := autho2.NewTokenHandlerWithOptions(cfg, ckCfg, exchangeOpts, errHandler, userHandler)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		// the user form value is only posted on the first sign in.
+		ctx := r.Context()
+		if raw := r.PostFormValue("user"); raw != "" {
+			var user struct {
+				Name *Name `json:"name"`
+			}
+			if err := json.Unmarshal([]byte(raw), &user); err != nil {
+				autho.PassError(err, errHandler, w, r)
+				return
+			}
+			if user.Name != nil {
+				ctx = context.WithValue(ctx, nameKey{}, user.Name)
+			}
+		}
+
+		tokenHandler.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewUserHandler creates a new apple UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. Apple has no userinfo endpoint, the
+// user is read from the verified id token of the token response and set under the request
+// context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*apple.User)
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	verifier := newVerifier(cfg, newOptions(opts))
+
+	fetch := func(ctx context.Context, _ *http.Client) (*User, error) {
+		tkn, err := autho2.TokenFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, ok := tkn.Extra("id_token").(string)
+		if !ok || raw == "" {
+			return nil, autho.ErrNoUser
+		}
+		idTkn, err := verifier.Verify(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return userFromIDToken(idTkn)
+	}
+
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
@@ -0,0 +1,92 @@
+package apple
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/oidctest"
+	"golang.org/x/oauth2"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	secret := &ClientSecret{TeamID: "TEAM123456", KeyID: "KEY1234567", ClientID: "com.example.web", Key: newTestKey(t)}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("client_id") != "com.example.web" || len(r.PostForm["client_secret"]) != 1 || strings.Count(r.PostForm.Get("client_secret"), ".") != 2 {
+			t.Errorf("expected generated client secret but got %q", r.PostForm["client_secret"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token": signer.Sign(map[string]interface{}{
+				"iss":            Issuer,
+				"sub":            "001234.abcd",
+				"aud":            "com.example.web",
+				"exp":            time.Now().Add(time.Hour).Unix(),
+				"email":          "jane@privaterelay.appleid.com",
+				"email_verified": "true",
+			}),
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "com.example.web",
+		Scopes:   []string{"name", "email"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   Endpoint.AuthURL,
+			TokenURL:  srv.URL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+	ckCfg := autho.NewDebugCookieConfig("state")
+
+	// login phase.
+	w := httptest.NewRecorder()
+	NewLoginHandler(cfg, ckCfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if loc.Query().Get("response_mode") != "form_post" {
+		t.Fatalf("expected form_post response mode but got %s", loc.RawQuery)
+	}
+	stateCk := w.Result().Cookies()[0]
+	if stateCk.SameSite != http.SameSiteNoneMode || !stateCk.Secure {
+		t.Fatalf("expected SameSite=None and Secure state cookie for the form_post callback")
+	}
+
+	// callback phase.
+	var gotUser *User
+	var gotName *Name
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+		gotName, _ = NameFromContext(r.Context())
+	})
+	h := NewCallbackHandler(cfg, ckCfg, nil, terminal, WithKeySet(signer.KeySet()), WithClientSecret(secret))
+
+	form := url.Values{
+		"code":  {"auth-code"},
+		"state": {stateCk.Value},
+		"user":  {`{"name":{"firstName":"Jane","lastName":"Doe"},"email":"jane@privaterelay.appleid.com"}`},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(stateCk)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if gotUser == nil || gotUser.ID != "001234.abcd" || !gotUser.EmailVerified {
+		t.Fatalf("unexpected user: %+v (%d: %s)", gotUser, w.Code, w.Body.String())
+	}
+	if gotName == nil || gotName.FirstName != "Jane" || gotName.LastName != "Doe" {
+		t.Fatalf("expected first login name but got %+v", gotName)
+	}
+}
@@ -0,0 +1,50 @@
+package apple
+
+import (
+	"github.com/Lambels/autho/oidc"
+)
+
+// Option configures the apple handlers.
+type Option func(*options)
+
+type options struct {
+	keySet       oidc.KeySet
+	audiences    []string
+	clientSecret *ClientSecret
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		keySet: defaultKeySet,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithKeySet sets the key set used to verify the signature of apple id tokens.
+//
+// Defaults to apples JSON Web Key Set which is fetched and cached by max-age.
+func WithKeySet(keySet oidc.KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+	}
+}
+
+// WithAudiences adds client ids accepted as the audience of id tokens besides cfg.ClientID.
+// Use it to accept identity tokens issued to the bundle id of your native app.
+func WithAudiences(clientIDs ...string) Option {
+	return func(o *options) {
+		o.audiences = append(o.audiences, clientIDs...)
+	}
+}
+
+// WithClientSecret sets the generator of the client secret sent with the token exchange, it
+// replaces cfg.ClientSecret.
+func WithClientSecret(secret *ClientSecret) Option {
+	return func(o *options) {
+		o.clientSecret = secret
+	}
+}
@@ -0,0 +1,138 @@
+package apple
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MaxSecretTTL is the maximum lifetime of a client secret accepted by apple.
+const MaxSecretTTL time.Duration = 180 * 24 * time.Hour
+
+// refreshMargin is how long before its expiry a client secret is regenerated, secrets living
+// less than 10 refresh margins are regenerated in the last tenth of their lifetime instead.
+const refreshMargin time.Duration = time.Hour
+
+// ClientSecret generates the client secret used by apple instead of a static secret, an ES256
+// JWT signed with the private key (.p8) of your apple developer account. The secret is cached
+// and regenerated before it expires.
+//
+// https://developer.apple.com/documentation/accountorganizationaldatasharing/creating-a-client-secret
+type ClientSecret struct {
+	// TeamID is the 10 character team id of your apple developer account.
+	TeamID string
+	// KeyID is the 10 character id of the private key.
+	KeyID string
+	// ClientID is your services id, usually cfg.ClientID.
+	ClientID string
+	// Key is the private key, see apple.ParsePrivateKey().
+	Key *ecdsa.PrivateKey
+	// TTL is the lifetime of the generated secrets, defaults to apple.MaxSecretTTL.
+	TTL time.Duration
+
+	// now is used to compute the expiry of the secret, defaults to time.Now.
+	now func() time.Time
+
+	mu     sync.Mutex
+	secret string
+	expiry time.Time
+}
+
+// ParsePrivateKey parses the PEM encoded private key (.p8 file) downloaded from your apple
+// developer account.
+func ParsePrivateKey(p8 []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(p8)
+	if block == nil {
+		return nil, errors.New("autho: private key is not pem encoded")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("autho: private key is not an ecdsa key")
+	}
+
+	return ecKey, nil
+}
+
+// Secret returns the current client secret, generating a new one if it is about to expire.
+func (s *ClientSecret) Secret() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+
+	ttl := s.TTL
+	if ttl <= 0 || ttl > MaxSecretTTL {
+		ttl = MaxSecretTTL
+	}
+	margin := refreshMargin
+	if ttl/10 < margin {
+		margin = ttl / 10
+	}
+	if s.secret != "" && now.Add(margin).Before(s.expiry) {
+		return s.secret, nil
+	}
+
+	expiry := now.Add(ttl)
+
+	secret, err := s.sign(now, expiry)
+	if err != nil {
+		return "", err
+	}
+	s.secret = secret
+	s.expiry = expiry
+
+	return secret, nil
+}
+
+func (s *ClientSecret) sign(issuedAt, expiry time.Time) (string, error) {
+	if s.Key == nil {
+		return "", errors.New("autho: client secret private key missing")
+	}
+
+	hdr, err := json.Marshal(map[string]string{
+		"alg": "ES256",
+		"kid": s.KeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"iss": s.TeamID,
+		"iat": issuedAt.Unix(),
+		"exp": expiry.Unix(),
+		"aud": Issuer,
+		"sub": s.ClientID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	r, ss, err := ecdsa.Sign(rand.Reader, s.Key, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// ES256 signatures are the fixed size concatenation of r and s.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	ss.FillBytes(sig[32:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
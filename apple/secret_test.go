@@ -0,0 +1,100 @@
+package apple
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho/oidc"
+)
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestClientSecret(t *testing.T) {
+	key := newTestKey(t)
+	now := time.Now()
+	s := &ClientSecret{
+		TeamID:   "TEAM123456",
+		KeyID:    "KEY1234567",
+		ClientID: "com.example.web",
+		Key:      key,
+		TTL:      24 * time.Hour,
+		now:      func() time.Time { return now },
+	}
+
+	secret, err := s.Secret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := oidc.NewVerifier(oidc.StaticKeySet{"KEY1234567": &key.PublicKey}, &oidc.Config{
+		ClientIDs: []string{Issuer},
+		Issuers:   []string{"TEAM123456"},
+	})
+	tkn, err := verifier.Verify(context.Background(), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tkn.Subject != "com.example.web" || tkn.Expiry.Unix() != now.Add(24*time.Hour).Unix() {
+		t.Fatalf("unexpected client secret claims: %+v", tkn)
+	}
+
+	if cached, _ := s.Secret(); cached != secret {
+		t.Fatal("expected client secret to be cached")
+	}
+
+	now = now.Add(24*time.Hour - refreshMargin)
+	if regenerated, _ := s.Secret(); regenerated == secret {
+		t.Fatal("expected client secret to be regenerated before its expiry")
+	}
+}
+
+func TestClientSecretShortTTL(t *testing.T) {
+	now := time.Now()
+	s := &ClientSecret{
+		TeamID:   "TEAM123456",
+		KeyID:    "KEY1234567",
+		ClientID: "com.example.web",
+		Key:      newTestKey(t),
+		TTL:      10 * time.Minute,
+		now:      func() time.Time { return now },
+	}
+
+	secret, err := s.Secret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a ttl shorter than the refresh margin is refreshed in the last tenth of its lifetime.
+	now = now.Add(8 * time.Minute)
+	if cached, _ := s.Secret(); cached != secret {
+		t.Fatal("expected short lived client secret to be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if regenerated, _ := s.Secret(); regenerated == secret {
+		t.Fatal("expected short lived client secret to be regenerated before its expiry")
+	}
+}
@@ -9,6 +9,27 @@ import (
 // ErrNoUser represents the user handler not being able to reach the user resoursce.
 var ErrNoUser error = errors.New("autho: unable to get user from provider")
 
+// UserError wraps the error encountered by a user handler while fetching the user resource from
+// the provider.
+//
+// errors.Is(err, autho.ErrNoUser) reports true for any *UserError, the underlying error is
+// accessible with errors.As or errors.Unwrap.
+type UserError struct {
+	Err error
+}
+
+func (e *UserError) Error() string {
+	return ErrNoUser.Error() + ": " + e.Err.Error()
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UserError) Is(target error) bool {
+	return target == ErrNoUser
+}
+
 // DefaultFailureHandle sends a response with error code: 400 (Bad Request) and the error text.
 var DefaultFailureHandle http.HandlerFunc = failureHandler
 
@@ -81,6 +102,10 @@ type CookieConfig struct {
 	Secure bool
 	// HttpOnly indicates to the browser if the cookie is accessable by client-side scripts.
 	HttpOnly bool
+	// SameSite sets the SameSite attribute of the cookie. Providers posting the callback
+	// (e.g. apple with response_mode=form_post) require http.SameSiteNoneMode with Secure, the
+	// apple login handler defaults to it when unset.
+	SameSite http.SameSite
 }
 
 func NewDebugCookieConfig(name string) *CookieConfig {
@@ -124,6 +149,7 @@ func newCookie(conf *CookieConfig) *http.Cookie {
 		MaxAge:   conf.MaxAge,
 		Secure:   conf.Secure,
 		HttpOnly: conf.HttpOnly,
+		SameSite: conf.SameSite,
 	}
 }
 
@@ -66,3 +66,15 @@ func (m *testMux) validate(t *testing.T, r Registerer) {
 		t.Fatal("didnt find expected handler or path")
 	}
 }
+
+func TestUserError(t *testing.T) {
+	cause := errors.New("provider unavailable")
+	var err error = &UserError{Err: cause}
+
+	if !errors.Is(err, ErrNoUser) {
+		t.Fatal("expected UserError to match ErrNoUser")
+	}
+	if !errors.Is(err, cause) {
+		t.Fatal("expected UserError to unwrap to its cause")
+	}
+}
@@ -0,0 +1,232 @@
+package autho
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ErrUserNotAuthorized represents a user rejected by an Authorizer. It is passed to the error
+// handler, use errors.As to recognise it.
+//
+//	var notAuthorized *autho.ErrUserNotAuthorized
+//	if errors.As(autho.ErrorFromContext(r.Context()), &notAuthorized) { ... }
+type ErrUserNotAuthorized struct {
+	// Reason describes why the user was rejected.
+	Reason string
+	// Err is the underlying error returned by a custom authorizer, if any.
+	Err error
+}
+
+func (e *ErrUserNotAuthorized) Error() string {
+	return "autho: user not authorized: " + e.Reason
+}
+
+func (e *ErrUserNotAuthorized) Unwrap() error {
+	return e.Err
+}
+
+// Authorizer decides if a user fetched by the user handler is allowed to sign in. A nil error
+// accepts the user, any other error rejects it.
+type Authorizer interface {
+	Authorize(ctx context.Context, user interface{}) error
+}
+
+// AuthorizerFunc is an adapter to use ordinary functions as Authorizers.
+type AuthorizerFunc func(ctx context.Context, user interface{}) error
+
+// Authorize calls f(ctx, user).
+func (f AuthorizerFunc) Authorize(ctx context.Context, user interface{}) error {
+	return f(ctx, user)
+}
+
+// NewAuthorizeHandler creates a new AuthorizeHandler which sits between the user handler and the
+// terminal handler. It runs authz against the user found under the request context, calling on
+// success the terminalHandler. Rejected users are passed to the errHandler as an
+// *ErrUserNotAuthorized.
+//
+// UserHandler -> AuthorizeHandler -> TerminalHandler
+//
+//	gh.NewCallbackHandler(cfg, ckCfg, nil, autho.NewAuthorizeHandler(
+//		autho.EmailDomains("example.com"),
+//		nil,
+//		terminalHandler,
+//	))
+func NewAuthorizeHandler(authz Authorizer, errHandler, terminalHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		user := UserFromContext(r.Context())
+		if user == nil {
+			PassError(ErrNoUser, errHandler, w, r)
+			return
+		}
+
+		if err := authz.Authorize(r.Context(), user); err != nil {
+			PassError(notAuthorized(err), errHandler, w, r)
+			return
+		}
+
+		terminalHandler.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(f)
+}
+
+func notAuthorized(err error) *ErrUserNotAuthorized {
+	if e, ok := err.(*ErrUserNotAuthorized); ok {
+		return e
+	}
+
+	return &ErrUserNotAuthorized{
+		Reason: err.Error(),
+		Err:    err,
+	}
+}
+
+// All creates an Authorizer which accepts the user only if all authzs accept it.
+func All(authzs ...Authorizer) Authorizer {
+	fn := func(ctx context.Context, user interface{}) error {
+		for _, authz := range authzs {
+			if err := authz.Authorize(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return AuthorizerFunc(fn)
+}
+
+// Any creates an Authorizer which accepts the user if at least one of authzs accepts it. The
+// error of the last authorizer is returned if all reject the user.
+func Any(authzs ...Authorizer) Authorizer {
+	fn := func(ctx context.Context, user interface{}) error {
+		err := error(&ErrUserNotAuthorized{Reason: "no authorizer accepted the user"})
+		for _, authz := range authzs {
+			if err = authz.Authorize(ctx, user); err == nil {
+				return nil
+			}
+		}
+
+		return err
+	}
+
+	return AuthorizerFunc(fn)
+}
+
+// EmailDomains creates an Authorizer which accepts users with a verified email under one of
+// domains.
+func EmailDomains(domains ...string) Authorizer {
+	fn := func(ctx context.Context, user interface{}) error {
+		id, ok := IdentityOf(ctx, user)
+		if !ok || id.Email == "" {
+			return &ErrUserNotAuthorized{Reason: "user has no email"}
+		}
+		if !id.EmailVerified {
+			return &ErrUserNotAuthorized{Reason: "user email is not verified"}
+		}
+
+		email := strings.ToLower(id.Email)
+		for _, domain := range domains {
+			if strings.HasSuffix(email, "@"+strings.ToLower(domain)) {
+				return nil
+			}
+		}
+
+		return &ErrUserNotAuthorized{Reason: "user email domain is not allowed"}
+	}
+
+	return AuthorizerFunc(fn)
+}
+
+// AllowIDs creates an Authorizer which accepts only the users with one of ids.
+func AllowIDs(ids ...string) Authorizer {
+	set := toSet(ids)
+
+	fn := func(ctx context.Context, user interface{}) error {
+		id, ok := IdentityOf(ctx, user)
+		if !ok || !set[id.ID] {
+			return &ErrUserNotAuthorized{Reason: "user is not in the allowlist"}
+		}
+
+		return nil
+	}
+
+	return AuthorizerFunc(fn)
+}
+
+// DenyIDs creates an Authorizer which rejects the users with one of ids.
+func DenyIDs(ids ...string) Authorizer {
+	set := toSet(ids)
+
+	fn := func(ctx context.Context, user interface{}) error {
+		id, ok := IdentityOf(ctx, user)
+		if !ok {
+			return &ErrUserNotAuthorized{Reason: "user has no identity"}
+		}
+		if set[id.ID] {
+			return &ErrUserNotAuthorized{Reason: "user is in the denylist"}
+		}
+
+		return nil
+	}
+
+	return AuthorizerFunc(fn)
+}
+
+func toSet(vals []string) map[string]bool {
+	set := make(map[string]bool, len(vals))
+	for _, v := range vals {
+		set[v] = true
+	}
+
+	return set
+}
+
+// Identity represents the provider independent identity of a user, used by the built in
+// authorizers.
+type Identity struct {
+	// ID is the providers unique identifier of the user.
+	ID            string
+	Email         string
+	EmailVerified bool
+}
+
+// Identifier is implemented by user types which can describe their identity.
+type Identifier interface {
+	Identity() Identity
+}
+
+// NewIdentityHandler creates a new handler which sets the identity of users of type T, described
+// by identify, under the request context before calling next. It is used by the user handlers of
+// providers whose user types can't implement Identifier (e.g. types from provider sdks).
+//
+//	terminalHandler = autho.NewIdentityHandler(identity, terminalHandler)
+func NewIdentityHandler[T any](identify func(user T) Identity, next http.Handler) http.Handler {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		user, ok := UserFromContextAs[T](r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		idCtx := ContextWithIdentity(r.Context(), identify(user))
+		next.ServeHTTP(w, r.WithContext(idCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// IdentityOf returns the identity of user, either described by the user (Identifier) or set
+// under ctx by the user handler. ok is false if the identity of the user is unknown.
+func IdentityOf(ctx context.Context, user interface{}) (id Identity, ok bool) {
+	if identifier, ok := user.(Identifier); ok {
+		return identifier.Identity(), true
+	}
+
+	return IdentityFromContext(ctx)
+}
@@ -0,0 +1,145 @@
+package autho
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testUser struct {
+	id Identity
+}
+
+func (u *testUser) Identity() Identity {
+	return u.id
+}
+
+func TestAuthorizeHandler(t *testing.T) {
+	tests := map[string]struct {
+		authz    Authorizer
+		user     *testUser
+		accepted bool
+	}{
+		"email domain": {
+			authz:    EmailDomains("example.com"),
+			user:     &testUser{id: Identity{ID: "1", Email: "Jane@Example.com", EmailVerified: true}},
+			accepted: true,
+		},
+		"unverified email domain": {
+			authz: EmailDomains("example.com"),
+			user:  &testUser{id: Identity{ID: "1", Email: "jane@example.com"}},
+		},
+		"foreign email domain": {
+			authz: EmailDomains("example.com"),
+			user:  &testUser{id: Identity{ID: "1", Email: "jane@example.com.evil.io", EmailVerified: true}},
+		},
+		"allowlist": {
+			authz:    AllowIDs("1", "2"),
+			user:     &testUser{id: Identity{ID: "2"}},
+			accepted: true,
+		},
+		"denylist": {
+			authz: All(AllowIDs("1", "2"), DenyIDs("2")),
+			user:  &testUser{id: Identity{ID: "2"}},
+		},
+		"any": {
+			authz:    Any(EmailDomains("example.com"), AllowIDs("3")),
+			user:     &testUser{id: Identity{ID: "3"}},
+			accepted: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = ErrorFromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(ContextWithUser(r.Context(), tc.user))
+			NewAuthorizeHandler(tc.authz, errHandler, terminal).ServeHTTP(httptest.NewRecorder(), r)
+
+			if called != tc.accepted {
+				t.Fatalf("expected accepted: %t but got %t", tc.accepted, called)
+			}
+			var notAuthorized *ErrUserNotAuthorized
+			if !tc.accepted && !errors.As(gotErr, &notAuthorized) {
+				t.Fatalf("expected ErrUserNotAuthorized but got %v", gotErr)
+			}
+		})
+	}
+}
+
+func TestAuthorizerFuncError(t *testing.T) {
+	cause := errors.New("banned")
+	authz := AuthorizerFunc(func(ctx context.Context, user interface{}) error {
+		return cause
+	})
+
+	var gotErr error
+	errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotErr = ErrorFromContext(r.Context())
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(ContextWithUser(r.Context(), &testUser{}))
+	NewAuthorizeHandler(authz, errHandler, &noopHandler{}).ServeHTTP(httptest.NewRecorder(), r)
+
+	var notAuthorized *ErrUserNotAuthorized
+	if !errors.As(gotErr, &notAuthorized) || !errors.Is(gotErr, cause) {
+		t.Fatalf("expected ErrUserNotAuthorized wrapping cause but got %v", gotErr)
+	}
+}
+
+// sdkUser represents a user type which can't implement Identifier.
+type sdkUser struct {
+	ID string
+}
+
+func TestIdentityHandler(t *testing.T) {
+	identify := func(u *sdkUser) Identity {
+		return Identity{ID: u.ID}
+	}
+
+	tests := map[string]struct {
+		handler  func(next http.Handler) http.Handler
+		accepted bool
+	}{
+		"identity set": {
+			handler: func(next http.Handler) http.Handler {
+				return NewIdentityHandler(identify, next)
+			},
+			accepted: true,
+		},
+		"identity unknown": {
+			handler: func(next http.Handler) http.Handler {
+				return next
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			h := tc.handler(NewAuthorizeHandler(AllowIDs("1"), errHandler, terminal))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(ContextWithUser(r.Context(), &sdkUser{ID: "1"}))
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if called != tc.accepted {
+				t.Fatalf("expected accepted: %t but got %t", tc.accepted, called)
+			}
+		})
+	}
+}
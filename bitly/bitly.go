@@ -1,20 +1,41 @@
 package bitly
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/Lambels/autho"
 	"golang.org/x/oauth2"
 )
 
-const profileEndpoint string = "https://api-ssl.bitly.com/v4/user"
+const apiURL string = "https://api-ssl.bitly.com/v4"
 
 var Endpoint *oauth2.Endpoint = &oauth2.Endpoint{
 	AuthURL:  "https://bitly.com/oauth/authorize",
 	TokenURL: "https://api-ssl.bitly.com/oauth/access_token",
 }
 
+// APIError represents an error returned by the bitly api.
+//
+// https://dev.bitly.com/docs/getting-started/errors
+type APIError struct {
+	Status      int    `json:"-"`
+	Message     string `json:"message"`
+	Description string `json:"description"`
+	Resource    string `json:"resource"`
+}
+
+func (e *APIError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("autho: bitly api error (%d): %s: %s", e.Status, e.Message, e.Description)
+	}
+
+	return fmt.Sprintf("autho: bitly api error (%d): %s", e.Status, e.Message)
+}
+
 // User represents fields accessible on a bitly account.
 //
 // https://dev.bitly.com/api-reference/#getUser
@@ -28,33 +49,110 @@ type User struct {
 	Emails           []*Email `json:"emails"`
 	Is2FAEnabled     bool     `json:"is_2fa_enabled"`
 	DefaultGroupGuid string   `json:"default_group_guid"`
+
+	// DefaultGroup and Organizations are only set when using bitly.WithOrganizations().
+	DefaultGroup  *Group          `json:"default_group,omitempty"`
+	Organizations []*Organization `json:"organizations,omitempty"`
 }
 
 // Email represents an email on the users account.
 type Email struct {
 	Email      string `json:"email"`
-	IsPrimary  string `json:"is_primary"`
-	IsVerified string `json:"is_verified"`
+	IsPrimary  bool   `json:"is_primary"`
+	IsVerified bool   `json:"is_verified"`
 }
 
-func me(client *http.Client) (*User, error) {
-	req, err := http.NewRequest(http.MethodGet, profileEndpoint, nil)
-	if err != nil {
-		return nil, err
+// Group represents a bitly group.
+//
+// https://dev.bitly.com/api-reference/#getGroup
+type Group struct {
+	Guid             string `json:"guid"`
+	Name             string `json:"name"`
+	OrganizationGuid string `json:"organization_guid"`
+	Role             string `json:"role"`
+	IsActive         bool   `json:"is_active"`
+	Created          string `json:"created"`
+	Modified         string `json:"modified"`
+}
+
+// Organization represents a bitly organization.
+//
+// https://dev.bitly.com/api-reference/#getOrganizations
+type Organization struct {
+	Guid     string `json:"guid"`
+	Name     string `json:"name"`
+	Tier     string `json:"tier"`
+	Role     string `json:"role"`
+	IsActive bool   `json:"is_active"`
+	Created  string `json:"created"`
+	Modified string `json:"modified"`
+}
+
+// Identity implements autho.Identifier using the primary email of the user.
+func (u *User) Identity() autho.Identity {
+	id := autho.Identity{
+		ID: u.Login,
+	}
+	for _, email := range u.Emails {
+		if email.IsPrimary {
+			id.Email = email.Email
+			id.EmailVerified = email.IsVerified
+		}
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	return id
+}
+
+func me(ctx context.Context, client *http.Client, o *options) (*User, error) {
+	var user User
+	if err := get(ctx, client, apiURL+"/user", &user); err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
+	if user.Login == "" {
 		return nil, autho.ErrNoUser
 	}
 
-	var user User
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return nil, autho.ErrNoUser
+	if o.organizations {
+		if user.DefaultGroupGuid != "" {
+			var group Group
+			if err := get(ctx, client, apiURL+"/groups/"+url.PathEscape(user.DefaultGroupGuid), &group); err != nil {
+				return nil, err
+			}
+			user.DefaultGroup = &group
+		}
+
+		var orgs struct {
+			Organizations []*Organization `json:"organizations"`
+		}
+		if err := get(ctx, client, apiURL+"/organizations", &orgs); err != nil {
+			return nil, err
+		}
+		user.Organizations = orgs.Organizations
 	}
 
 	return &user, nil
 }
+
+// get fetches the bitly api resource at u into v, error responses are returned as an *APIError.
+func get(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,79 @@
+package bitly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lambels/autho/internal/apitest"
+)
+
+func TestMe(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"login":              "jane",
+			"default_group_guid": "Ba1bc23dE4F",
+			"emails": []map[string]interface{}{
+				{"email": "old@example.com", "is_primary": false, "is_verified": true},
+				{"email": "jane@example.com", "is_primary": true, "is_verified": true},
+			},
+		})
+	})
+	mux.HandleFunc("/v4/groups/Ba1bc23dE4F", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"guid":              "Ba1bc23dE4F",
+			"name":              "Default",
+			"organization_guid": "Oa1bc23dE4F",
+		})
+	})
+	mux.HandleFunc("/v4/organizations", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"organizations": []map[string]interface{}{
+				{"guid": "Oa1bc23dE4F", "name": "Acme"},
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	user, err := me(context.Background(), apitest.Client(srv), newOptions([]Option{WithOrganizations()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := user.Identity()
+	if id.ID != "jane" || id.Email != "jane@example.com" || !id.EmailVerified {
+		t.Fatalf("unexpected identity: %+v", id)
+	}
+	if user.DefaultGroup == nil || user.DefaultGroup.Name != "Default" {
+		t.Fatalf("expected default group but got %+v", user.DefaultGroup)
+	}
+	if len(user.Organizations) != 1 || user.Organizations[0].Name != "Acme" {
+		t.Fatalf("expected organizations but got %+v", user.Organizations)
+	}
+}
+
+func TestMeAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":     "FORBIDDEN",
+			"description": "You are currently forbidden to access this resource.",
+		})
+	}))
+	defer srv.Close()
+
+	_, err := me(context.Background(), apitest.Client(srv), newOptions(nil))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError but got %v", err)
+	}
+	if apiErr.Status != http.StatusForbidden || apiErr.Message != "FORBIDDEN" {
+		t.Fatalf("unexpected error: %+v", apiErr)
+	}
+}
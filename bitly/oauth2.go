@@ -1,6 +1,7 @@
 package bitly
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Lambels/autho"
@@ -14,7 +15,7 @@ import (
 //
 // This method saves allot of boilerplate. For more customisable handlers construct your
 // own callback handler by wrapping your own specific token handler around your own specific user handler.
-func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
 	return NewTokenHandler(
 		cfg,
 		ckCfg,
@@ -23,6 +24,7 @@ func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandle
 			cfg,
 			errHandler,
 			terminalHandler,
+			opts...,
 		),
 	)
 }
@@ -43,30 +45,22 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, callbackHandler)
 }
 
-func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
-	}
-
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho2.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
-
-		user, err := me(cfg.Client(
-			r.Context(),
-			tkn,
-		))
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
+// NewUserHandler creates a new bitly UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The user resource is set under the
+// request context.
+//
+// Error responses of the bitly api are passed to the errHandler as an *autho.UserError wrapping
+// a *bitly.APIError.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*bitly.User)
+//
+// The UserModel used by default by the bitly.NewUserHandler is: https://dev.bitly.com/api-reference/#getUser
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
 
-		userCtx := autho.ContextWithUser(r.Context(), user)
-		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	fetch := func(ctx context.Context, client *http.Client) (*User, error) {
+		return me(ctx, client, o)
 	}
 
-	return http.HandlerFunc(f)
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
 }
@@ -0,0 +1,28 @@
+package bitly
+
+// Option configures the bitly handlers.
+type Option func(*options)
+
+type options struct {
+	organizations bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithOrganizations fetches the default group and the organizations of the user alongside the
+// user, they are set under User.DefaultGroup and User.Organizations.
+//
+// https://dev.bitly.com/api-reference/#getGroup
+// https://dev.bitly.com/api-reference/#getOrganizations
+func WithOrganizations() Option {
+	return func(o *options) {
+		o.organizations = true
+	}
+}
@@ -35,3 +35,27 @@ func ContextWithUser(ctx context.Context, user interface{}) context.Context {
 func UserFromContext(ctx context.Context) interface{} {
 	return ctx.Value(userKey{})
 }
+
+// UserFromContextAs harvests the user from the request context as type T.
+// If no user is set or the user isn't of type T, ok is false.
+//
+//	user, ok := autho.UserFromContextAs[*github.User](r.Context())
+func UserFromContextAs[T any](ctx context.Context) (user T, ok bool) {
+	user, ok = ctx.Value(userKey{}).(T)
+	return user, ok
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity adds the identity of the user to the context to be used by the authorizers,
+// see autho.NewIdentityHandler().
+func ContextWithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext harvests the identity of the user from the request context, ok is false if
+// no identity is set. Use autho.IdentityOf() to also consider users implementing Identifier.
+func IdentityFromContext(ctx context.Context) (id Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
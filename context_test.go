@@ -30,3 +30,25 @@ func TestContextWithUser(t *testing.T) {
 		t.Fatalf("expected user name: testing but got %s", gotUser.name)
 	}
 }
+
+func TestUserFromContextAs(t *testing.T) {
+	type userType struct {
+		name string
+	}
+	ctx := ContextWithUser(context.Background(), &userType{name: "testing"})
+
+	gotUser, ok := UserFromContextAs[*userType](ctx)
+	if !ok {
+		t.Fatal("couldnt parse return value")
+	}
+	if gotUser.name != "testing" {
+		t.Fatalf("expected user name: testing but got %s", gotUser.name)
+	}
+
+	if _, ok := UserFromContextAs[string](ctx); ok {
+		t.Fatal("expected mismatched user type to not be ok")
+	}
+	if _, ok := UserFromContextAs[*userType](context.Background()); ok {
+		t.Fatal("expected missing user to not be ok")
+	}
+}
@@ -0,0 +1,38 @@
+package discord
+
+import "context"
+
+type extrasKey struct{}
+
+// extras holds the resources fetched alongside the user by the user handler.
+type extras struct {
+	guilds  []*Guild
+	members map[string]*Member
+}
+
+func contextWithExtras(ctx context.Context, ex *extras) context.Context {
+	return context.WithValue(ctx, extrasKey{}, ex)
+}
+
+// GuildsFromContext harvests the guilds the user is a member of from the request context, they
+// are set by the user handler when using the discord.WithGuilds() option.
+func GuildsFromContext(ctx context.Context) ([]*Guild, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.guilds == nil {
+		return nil, false
+	}
+
+	return ex.guilds, true
+}
+
+// MembersFromContext harvests the guild members of the user by guild id from the request
+// context, they are set by the user handler when using the discord.WithGuildMembers() option.
+// Guilds the user isn't a member of are missing from the map.
+func MembersFromContext(ctx context.Context) (map[string]*Member, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.members == nil {
+		return nil, false
+	}
+
+	return ex.members, true
+}
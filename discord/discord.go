@@ -0,0 +1,222 @@
+// Package discord provides handlers for discord with guild (server) membership and role gating.
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+// apiURL is the base url of the discord api v10.
+const apiURL string = "https://discord.com/api/v10"
+
+// Endpoint is the discord oauth2 endpoint.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://discord.com/oauth2/authorize",
+	TokenURL: "https://discord.com/api/oauth2/token",
+}
+
+// User represents fields accessible on a discord account.
+//
+// https://discord.com/developers/docs/resources/user#user-object
+type User struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	GlobalName    string `json:"global_name"`
+	Discriminator string `json:"discriminator"`
+	Avatar        string `json:"avatar"`
+	Bot           bool   `json:"bot"`
+	MFAEnabled    bool   `json:"mfa_enabled"`
+	Locale        string `json:"locale"`
+	// Email is only set with the email scope, check Verified before trusting it.
+	Email    string `json:"email"`
+	Verified bool   `json:"verified"`
+}
+
+// Identity implements autho.Identifier, the email is only reported as verified if discord
+// verified it.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:            u.ID,
+		Email:         u.Email,
+		EmailVerified: u.Email != "" && u.Verified,
+	}
+}
+
+// Guild represents a partial guild (server) the user is a member of.
+//
+// https://discord.com/developers/docs/resources/user#get-current-user-guilds
+type Guild struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Owner bool   `json:"owner"`
+	// Permissions is the permission bit set of the user in the guild.
+	Permissions string `json:"permissions"`
+}
+
+// Member represents the user as a member of a guild.
+//
+// https://discord.com/developers/docs/resources/guild#guild-member-object
+type Member struct {
+	Nick     string   `json:"nick"`
+	Roles    []string `json:"roles"`
+	JoinedAt string   `json:"joined_at"`
+}
+
+// APIError represents an error returned by the discord api.
+//
+// https://discord.com/developers/docs/topics/opcodes-and-status-codes#json
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("autho: discord api error (%d): %s", e.Status, e.Message)
+}
+
+// me fetches the user alongside the resources enabled by the options.
+func me(ctx context.Context, client *http.Client, o *options) (*User, *extras, error) {
+	var user User
+	if err := get(ctx, client, apiURL+"/users/@me", &user); err != nil {
+		return nil, nil, err
+	}
+	if user.ID == "" {
+		return nil, nil, autho.ErrNoUser
+	}
+	if o.verifiedEmail && (user.Email == "" || !user.Verified) {
+		return nil, nil, &autho.ErrUserNotAuthorized{Reason: "discord email is not verified"}
+	}
+
+	ex := &extras{}
+	if o.guilds {
+		guilds, err := listGuilds(ctx, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		ex.guilds = guilds
+	}
+	if len(o.members) > 0 {
+		ex.members = make(map[string]*Member, len(o.members))
+		for _, guildID := range o.members {
+			member, err := guildMember(ctx, client, guildID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if member != nil {
+				ex.members[guildID] = member
+			}
+		}
+	}
+
+	if err := authorize(ex, o); err != nil {
+		return nil, nil, err
+	}
+
+	return &user, ex, nil
+}
+
+func listGuilds(ctx context.Context, client *http.Client) ([]*Guild, error) {
+	var guilds []*Guild
+
+	// guilds are paginated by id, 200 at a time.
+	after := ""
+	for {
+		params := url.Values{"limit": {"200"}}
+		if after != "" {
+			params.Set("after", after)
+		}
+
+		var page []*Guild
+		if err := get(ctx, client, apiURL+"/users/@me/guilds?"+params.Encode(), &page); err != nil {
+			return nil, err
+		}
+		guilds = append(guilds, page...)
+
+		if len(page) < 200 {
+			return guilds, nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+// guildMember fetches the member of the user in guildID, nil if the user isn't a member.
+func guildMember(ctx context.Context, client *http.Client, guildID string) (*Member, error) {
+	var member Member
+	err := get(ctx, client, apiURL+"/users/@me/guilds/"+url.PathEscape(guildID)+"/member", &member)
+	if apiErr, ok := err.(*APIError); ok && apiErr.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// authorize checks the guilds and members of the user against the required guilds and roles.
+func authorize(ex *extras, o *options) error {
+	if len(o.requireGuilds) > 0 {
+		var member bool
+		for _, guild := range ex.guilds {
+			if contains(o.requireGuilds, guild.ID) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return &autho.ErrUserNotAuthorized{Reason: "user is not a member of the required discord guilds"}
+		}
+	}
+
+	for guildID, roles := range o.requireRoles {
+		member, ok := ex.members[guildID]
+		if !ok {
+			return &autho.ErrUserNotAuthorized{Reason: "user is not a member of discord guild " + guildID}
+		}
+
+		var hasRole bool
+		for _, role := range member.Roles {
+			if contains(roles, role) {
+				hasRole = true
+				break
+			}
+		}
+		if !hasRole {
+			return &autho.ErrUserNotAuthorized{Reason: "user lacks the required roles in discord guild " + guildID}
+		}
+	}
+
+	return nil
+}
+
+// get fetches the discord api resource at u into v, error responses are returned as an *APIError.
+func get(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,66 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler discord.NewTokenHandler()
+// wrapped around the default discord.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg)
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+}
+
+// NewUserHandler creates a new discord UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The user resource is set under the
+// request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*discord.User)
+//
+// The UserModel used by default by the discord.NewUserHandler is: https://discord.com/developers/docs/resources/user#user-object
+//
+// Additional resources fetched alongside the user (see the discord Options) are set under the
+// request context, e.g. discord.GuildsFromContext().
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	fetch := func(ctx context.Context, client *http.Client) (*User, *extras, error) {
+		return me(ctx, client, o)
+	}
+
+	return autho2.NewUserHandlerFuncWithExtras(cfg, fetch, contextWithExtras, errHandler, terminalHandler)
+}
@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// newAPIServer fakes the discord api for a user in guild "1" with role "mod".
+func newAPIServer(t *testing.T, verified bool) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v10/users/@me", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":       "80351110224678912",
+			"username": "jane",
+			"email":    "jane@example.com",
+			"verified": verified,
+		})
+	})
+	mux.HandleFunc("/api/v10/users/@me/guilds", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"id": "1", "name": "Community"},
+		})
+	})
+	mux.HandleFunc("/api/v10/users/@me/guilds/1/member", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"nick":  "janed",
+			"roles": []string{"member", "mod"},
+		})
+	})
+	mux.HandleFunc("/api/v10/users/@me/guilds/2/member", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 10004, "message": "Unknown Guild"})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func serveUserHandler(h http.Handler, srv *httptest.Server) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), &oauth2.Token{AccessToken: "access-token"}))
+	h.ServeHTTP(httptest.NewRecorder(), apitest.Request(r, srv))
+}
+
+func TestUserHandlerGating(t *testing.T) {
+	tests := map[string]struct {
+		opts     []Option
+		verified bool
+		accepted bool
+	}{
+		"required guild":       {opts: []Option{RequireGuilds("1")}, accepted: true},
+		"other guild":          {opts: []Option{RequireGuilds("2")}},
+		"required role":        {opts: []Option{RequireRoles("1", "admin", "mod")}, accepted: true},
+		"missing role":         {opts: []Option{RequireRoles("1", "admin")}},
+		"not a guild member":   {opts: []Option{RequireRoles("2", "mod")}},
+		"verified email":       {opts: []Option{RequireVerifiedEmail()}, verified: true, accepted: true},
+		"unverified email":     {opts: []Option{RequireVerifiedEmail()}},
+		"unverified, optional": {accepted: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newAPIServer(t, tc.verified)
+
+			var gotUser *User
+			var gotMembers map[string]*Member
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+				gotMembers, _ = MembersFromContext(r.Context())
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewUserHandler(&oauth2.Config{}, errHandler, terminal, tc.opts...)
+
+			serveUserHandler(h, srv)
+
+			if !tc.accepted {
+				var notAuthorized *autho.ErrUserNotAuthorized
+				if gotUser != nil || !errors.As(gotErr, &notAuthorized) {
+					t.Fatalf("expected *autho.ErrUserNotAuthorized but got %v", gotErr)
+				}
+				return
+			}
+
+			if gotUser == nil {
+				t.Fatalf("expected user to be accepted but got %v", gotErr)
+			}
+			if gotUser.Identity().EmailVerified != tc.verified {
+				t.Fatalf("expected identity email verified to be %v", tc.verified)
+			}
+			if m, ok := gotMembers["1"]; ok && m.Nick != "janed" {
+				t.Fatalf("unexpected guild member: %+v", m)
+			}
+		})
+	}
+}
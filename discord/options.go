@@ -0,0 +1,90 @@
+package discord
+
+// Option configures the discord handlers.
+type Option func(*options)
+
+type options struct {
+	guilds bool
+	// guild ids of the fetched guild members.
+	members       []string
+	requireGuilds []string
+	// required role ids by guild id.
+	requireRoles  map[string][]string
+	verifiedEmail bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		requireRoles: make(map[string][]string),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithGuilds fetches the guilds (servers) the user is a member of, which requires the guilds
+// scope. They are accessible with discord.GuildsFromContext().
+func WithGuilds() Option {
+	return func(o *options) {
+		o.guilds = true
+	}
+}
+
+// WithGuildMembers fetches the guild member (nickname, roles) of the user in each of guildIDs,
+// which requires the guilds.members.read scope. They are accessible with
+// discord.MembersFromContext().
+func WithGuildMembers(guildIDs ...string) Option {
+	return func(o *options) {
+		o.members = appendUnique(o.members, guildIDs...)
+	}
+}
+
+// RequireGuilds restricts sign in to members of at least one of guildIDs, other users are passed
+// to the error handler as an *autho.ErrUserNotAuthorized. It implies discord.WithGuilds().
+func RequireGuilds(guildIDs ...string) Option {
+	return func(o *options) {
+		o.guilds = true
+		o.requireGuilds = append(o.requireGuilds, guildIDs...)
+	}
+}
+
+// RequireRoles restricts sign in to members of guildID with at least one of roleIDs, other users
+// are passed to the error handler as an *autho.ErrUserNotAuthorized. It implies
+// discord.WithGuildMembers(guildID), each guild with required roles must be satisfied.
+func RequireRoles(guildID string, roleIDs ...string) Option {
+	return func(o *options) {
+		o.members = appendUnique(o.members, guildID)
+		o.requireRoles[guildID] = append(o.requireRoles[guildID], roleIDs...)
+	}
+}
+
+// RequireVerifiedEmail rejects users without a verified email, which requires the email scope.
+// Without it the email of unverified users is still set on the user but reported as unverified
+// by User.Identity().
+func RequireVerifiedEmail() Option {
+	return func(o *options) {
+		o.verifiedEmail = true
+	}
+}
+
+func appendUnique(set []string, vals ...string) []string {
+	for _, v := range vals {
+		if !contains(set, v) {
+			set = append(set, v)
+		}
+	}
+
+	return set
+}
+
+func contains(set []string, s string) bool {
+	for _, v := range set {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+package facebook
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	fb "github.com/huandu/facebook/v2"
+	"golang.org/x/oauth2"
+)
+
+// NewAccessTokenHandler creates a new handler which signs in users with a facebook user access
+// token obtained by a native sdk (android, ios, ...) and posted under the "access_token" form
+// value. The access token is inspected with the graph api debug_token endpoint to verify that
+// it is valid and that it was issued to your app (cfg.ClientID), following the inspection the
+// token is added to the request context and the default facebook.NewUserHandler() is called.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*facebook.User)
+func NewAccessTokenHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	o := newOptions(opts)
+	userHandler := NewUserHandler(cfg, errHandler, terminalHandler, opts...)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			autho.PassError(errors.New("autho: access token must be posted."), errHandler, w, r)
+			return
+		}
+
+		accessToken := r.PostFormValue("access_token")
+		if accessToken == "" {
+			autho.PassError(errors.New("autho: access token missing."), errHandler, w, r)
+			return
+		}
+
+		session := fb.New(cfg.ClientID, cfg.ClientSecret).Session(accessToken)
+		session.Version = o.version
+		session.HttpClient = oauth2.NewClient(r.Context(), nil)
+		res, err := session.WithContext(r.Context()).Inspect()
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		var info struct {
+			AppID   string `json:"app_id"`
+			UserID  string `json:"user_id"`
+			IsValid bool   `json:"is_valid"`
+		}
+		if err := res.Decode(&info); err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+		if !info.IsValid || info.UserID == "" {
+			autho.PassError(errors.New("autho: access token is invalid."), errHandler, w, r)
+			return
+		}
+		if info.AppID != cfg.ClientID {
+			autho.PassError(errors.New("autho: access token issued for another app."), errHandler, w, r)
+			return
+		}
+
+		tkn := &oauth2.Token{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+		}
+		tknCtx := autho2.ContextWithToken(r.Context(), tkn)
+		userHandler.ServeHTTP(w, r.WithContext(tknCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
@@ -0,0 +1,114 @@
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	"golang.org/x/oauth2"
+)
+
+// newGraphServer fakes the debug_token and me endpoints of the graph api.
+func newGraphServer(t *testing.T, appID string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+DefaultVersion+"/debug_token", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("access_token"); got != "app-id|app-secret" {
+			t.Errorf("expected app access token but got %s", got)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"app_id":   appID,
+				"user_id":  "42",
+				"is_valid": r.URL.Query().Get("input_token") == "user-token",
+			},
+		})
+	})
+	mux.HandleFunc("/"+DefaultVersion+"/me", func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha256.New, []byte("app-secret"))
+		mac.Write([]byte("user-token"))
+		if got, want := r.URL.Query().Get("appsecret_proof"), hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("expected appsecret_proof %s but got %s", want, got)
+		}
+		if got := r.URL.Query().Get("fields"); got != strings.Join(DefaultFields, ",") {
+			t.Errorf("expected default fields but got %s", got)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":   "42",
+			"name": "Jane Doe",
+			"picture": map[string]interface{}{
+				"data": map[string]interface{}{
+					"url": "https://graph.example.com/42.jpg",
+				},
+			},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAccessTokenHandler(t *testing.T) {
+	srv := newGraphServer(t, "app-id")
+	cfg := &oauth2.Config{ClientID: "app-id", ClientSecret: "app-secret"}
+
+	var gotUser *User
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+	})
+	h := NewAccessTokenHandler(cfg, nil, terminal)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, apitest.Request(postForm(url.Values{"access_token": {"user-token"}}), srv))
+
+	if gotUser == nil {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser.ID != "42" || gotUser.Name != "Jane Doe" || gotUser.PictureURL != "https://graph.example.com/42.jpg" {
+		t.Fatalf("unexpected user: %+v", gotUser)
+	}
+}
+
+func TestAccessTokenHandlerRejects(t *testing.T) {
+	tests := map[string]struct {
+		graphAppID string
+		token      string
+	}{
+		"invalid token": {graphAppID: "app-id", token: "forged-token"},
+		"foreign app":   {graphAppID: "other-app", token: "user-token"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newGraphServer(t, tc.graphAppID)
+			cfg := &oauth2.Config{ClientID: "app-id", ClientSecret: "app-secret"}
+
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("terminal handler called with rejected token")
+			})
+			h := NewAccessTokenHandler(cfg, nil, terminal)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, apitest.Request(postForm(url.Values{"access_token": {tc.token}}), srv))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status code 400 but got %d", w.Code)
+			}
+		})
+	}
+}
+
+func postForm(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
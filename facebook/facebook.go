@@ -1,5 +1,9 @@
 package facebook
 
+import (
+	"github.com/Lambels/autho"
+)
+
 // User represents fields accessible on public facebook accounts.
 //
 // https://developers.facebook.com/docs/graph-api/reference/user/#default-public-profile-fields
@@ -10,4 +14,16 @@ type User struct {
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
 	MiddleName string `json:"middle_name"`
+	// PictureURL is the url of the users profile picture, set when the "picture" field is
+	// requested.
+	PictureURL string `json:"picture_url"`
+}
+
+// Identity implements autho.Identifier. The email is reported as unverified, facebook doesn't
+// guarantee that the returned email was confirmed.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:    u.ID,
+		Email: u.Email,
+	}
 }
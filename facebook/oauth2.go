@@ -1,6 +1,7 @@
 package facebook
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Lambels/autho"
@@ -15,7 +16,7 @@ import (
 //
 // This method saves allot of boilerplate. For more customisable handlers construct your
 // own callback handler by wrapping your own specific token handler around your own specific user handler.
-func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
 	return NewTokenHandler(
 		cfg,
 		ckCfg,
@@ -24,7 +25,9 @@ func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandle
 			cfg,
 			errHandler,
 			terminalHandler,
+			opts...,
 		),
+		opts...,
 	)
 }
 
@@ -40,7 +43,15 @@ func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler
 // and state then comparing the cookie state with the request state. Following the parsing the
 // TokenHandler performs the token exchange and adds the token to the request context, calling on
 // success the UserHandler.
-func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+//
+// When using facebook.WithLongLivedToken() the token is swapped for a long lived one before
+// calling the UserHandler.
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	if o.longLived {
+		userHandler = newLongLivedTokenHandler(cfg, o, errHandler, userHandler)
+	}
+
 	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
 }
 
@@ -48,53 +59,75 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 // by the TokenHandler in exchange for the users resource. The user resource is set under the
 // request context.
 //
+// The fields requested from the graph api can be set with facebook.WithFields(), when
+// cfg.ClientSecret is set every graph api call is signed with the appsecret_proof parameter.
+//
 //	user, ok := autho.UserFromContext(r.Context()).(*facebook.User)
 //
 // The UserModel used by default by the facebook.NewUserHandler is: https://developers.facebook.com/docs/graph-api/reference/user/#default-public-profile-fields
-func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	fetch := func(ctx context.Context, client *http.Client) (*User, error) {
+		return me(ctx, client, cfg, o)
 	}
 
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho2.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
 
-		httpClient := cfg.Client(r.Context(), tkn)
-		session := &fb.Session{
-			Version:    "v2.4",
-			HttpClient: httpClient,
-		}
-		res, err := session.Get("/me", nil)
-		if err != nil {
-			// facebook api error.
-			if e, ok := err.(*fb.Error); ok {
-				autho.PassError(e, errHandler, w, r)
-				return
-			}
-
-			// facebook unmarshal error.
-			if e, ok := err.(*fb.UnmarshalError); ok {
-				autho.PassError(e, errHandler, w, r)
-				return
-			}
-
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
+// newSession creates a new graph api session for the access token. The session sends the
+// appsecret_proof parameter when the app secret is known.
+func newSession(cfg *oauth2.Config, accessToken string, client *http.Client, o *options) *fb.Session {
+	var session *fb.Session
+	if cfg.ClientSecret != "" && accessToken != "" {
+		session = fb.New(cfg.ClientID, cfg.ClientSecret).Session(accessToken)
+		session.EnableAppsecretProof(true)
+	} else {
+		session = &fb.Session{}
+	}
+	session.Version = o.version
+	session.HttpClient = client
+
+	return session
+}
+
+func me(ctx context.Context, client *http.Client, cfg *oauth2.Config, o *options) (*User, error) {
+	var accessToken string
+	if tkn, err := autho2.TokenFromContext(ctx); err == nil {
+		accessToken = tkn.AccessToken
+	}
+
+	session := newSession(cfg, accessToken, client, o)
+	res, err := session.WithContext(ctx).Get("/me", fb.Params{
+		"fields": o.fieldsParam(),
+	})
+	if err != nil {
+		// facebook api error.
+		if e, ok := err.(*fb.Error); ok {
+			return nil, e
 		}
 
-		var user User
-		if err := res.Decode(&user); err != nil {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
+		// facebook unmarshal error.
+		if e, ok := err.(*fb.UnmarshalError); ok {
+			return nil, e
 		}
 
-		userCtx := autho.ContextWithUser(r.Context(), &user)
-		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+		return nil, autho.ErrNoUser
+	}
+
+	var user User
+	if err := res.Decode(&user); err != nil {
+		return nil, autho.ErrNoUser
+	}
+
+	var picture struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := res.DecodeField("picture", &picture); err == nil {
+		user.PictureURL = picture.Data.URL
 	}
 
-	return http.HandlerFunc(f)
+	return &user, nil
 }
@@ -0,0 +1,67 @@
+package facebook
+
+import "strings"
+
+// DefaultVersion is the graph api version used by default by the facebook handlers.
+const DefaultVersion string = "v23.0"
+
+// DefaultFields are the user fields requested by default by the facebook.NewUserHandler.
+var DefaultFields []string = []string{"id", "name", "email", "first_name", "last_name", "middle_name", "picture"}
+
+// Option configures the facebook handlers.
+type Option func(*options)
+
+type options struct {
+	version string
+	fields  []string
+	// exchange the short lived user token for a long lived one.
+	longLived bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		version: DefaultVersion,
+		fields:  DefaultFields,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithVersion sets the graph api version (e.g. "v23.0") used by the facebook handlers.
+//
+// Default: facebook.DefaultVersion
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithFields sets the user fields requested from the graph api /me endpoint. Fields outside of
+// the facebook.User model can be read by building your own user handler.
+//
+// https://developers.facebook.com/docs/graph-api/reference/user/#fields
+//
+// Default: facebook.DefaultFields
+func WithFields(fields ...string) Option {
+	return func(o *options) {
+		o.fields = fields
+	}
+}
+
+// WithLongLivedToken swaps the short lived user access token obtained by the token handler for
+// a long lived one (about 60 days) before the user handler is called. The long lived token is
+// the one found under the request context.
+//
+// https://developers.facebook.com/docs/facebook-login/guides/access-tokens/get-long-lived
+func WithLongLivedToken() Option {
+	return func(o *options) {
+		o.longLived = true
+	}
+}
+
+func (o *options) fieldsParam() string {
+	return strings.Join(o.fields, ",")
+}
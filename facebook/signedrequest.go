@@ -0,0 +1,136 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	fb "github.com/huandu/facebook/v2"
+	"golang.org/x/oauth2"
+)
+
+// SignedRequest represents the payload of a facebook signed_request, posted by facebook to the
+// deauthorize and data deletion callbacks.
+//
+// https://developers.facebook.com/docs/games/gamesonfacebook/login#parsingsr
+type SignedRequest struct {
+	Algorithm string `json:"algorithm"`
+	IssuedAt  int64  `json:"issued_at"`
+	// UserID is the app scoped id of the user.
+	UserID     string `json:"user_id"`
+	Expires    int64  `json:"expires"`
+	OAuthToken string `json:"oauth_token"`
+}
+
+// ParseSignedRequest verifies the HMAC-SHA256 signature of signedRequest with the app secret
+// (cfg.ClientSecret) and decodes its payload. An empty app secret is rejected as anyone could
+// sign the request with it.
+func ParseSignedRequest(cfg *oauth2.Config, signedRequest string) (*SignedRequest, error) {
+	if cfg.ClientSecret == "" {
+		return nil, errors.New("autho: app secret required to verify the signed request.")
+	}
+
+	res, err := fb.New(cfg.ClientID, cfg.ClientSecret).ParseSignedRequest(signedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var req SignedRequest
+	if err := res.Decode(&req); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+type signedRequestKey struct{}
+
+// SignedRequestFromContext harvests the verified signed request from the request context, it is
+// set by the facebook.NewDeauthorizeHandler().
+func SignedRequestFromContext(ctx context.Context) (*SignedRequest, bool) {
+	req, ok := ctx.Value(signedRequestKey{}).(*SignedRequest)
+	return req, ok
+}
+
+// NewDeauthorizeHandler creates a new handler for the deauthorize callback, called by facebook
+// when a user removes your app. The posted signed_request is verified and set under the request
+// context before calling the terminalHandler, which should forget the user.
+//
+//	req, ok := facebook.SignedRequestFromContext(r.Context())
+//
+// https://developers.facebook.com/docs/facebook-login/guides/advanced/deauthorize-callback
+func NewDeauthorizeHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseSignedRequestForm(cfg, r)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		reqCtx := context.WithValue(r.Context(), signedRequestKey{}, req)
+		terminalHandler.ServeHTTP(w, r.WithContext(reqCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// DataDeletionFunc starts the deletion of the data of the user identified by req.UserID. It
+// returns the url where the user can check the status of the deletion and the confirmation code
+// of the deletion request.
+type DataDeletionFunc func(ctx context.Context, req *SignedRequest) (statusURL, confirmationCode string, err error)
+
+// NewDataDeletionHandler creates a new handler for the data deletion request callback, called
+// by facebook when a user requests the deletion of their data. The posted signed_request is
+// verified before calling fn, the status url and confirmation code returned by fn are then
+// written as the json response expected by facebook.
+//
+// https://developers.facebook.com/docs/development/create-an-app/app-dashboard/data-deletion-callback
+func NewDataDeletionHandler(cfg *oauth2.Config, fn DataDeletionFunc, errHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseSignedRequestForm(cfg, r)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		statusURL, code, err := fn(r.Context(), req)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			URL              string `json:"url"`
+			ConfirmationCode string `json:"confirmation_code"`
+		}{
+			URL:              statusURL,
+			ConfirmationCode: code,
+		})
+	}
+
+	return http.HandlerFunc(f)
+}
+
+func parseSignedRequestForm(cfg *oauth2.Config, r *http.Request) (*SignedRequest, error) {
+	if r.Method != http.MethodPost {
+		return nil, errors.New("autho: signed request must be posted.")
+	}
+
+	signedRequest := r.PostFormValue("signed_request")
+	if signedRequest == "" {
+		return nil, errors.New("autho: signed request missing.")
+	}
+
+	return ParseSignedRequest(cfg, signedRequest)
+}
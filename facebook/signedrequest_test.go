@@ -0,0 +1,101 @@
+package facebook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func signRequest(secret string, payload map[string]interface{}) string {
+	b, _ := json.Marshal(payload)
+	encoded := base64.RawURLEncoding.EncodeToString(b)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + encoded
+}
+
+func TestDeauthorizeHandler(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "app-id", ClientSecret: "app-secret"}
+	payload := map[string]interface{}{
+		"algorithm": "HMAC-SHA256",
+		"issued_at": 1700000000,
+		"user_id":   "42",
+	}
+
+	var gotReq *SignedRequest
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq, _ = SignedRequestFromContext(r.Context())
+	})
+	h := NewDeauthorizeHandler(cfg, nil, terminal)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"signed_request": {signRequest("app-secret", payload)}}))
+	if gotReq == nil || gotReq.UserID != "42" {
+		t.Fatalf("expected signed request for user 42 but got %+v (%d: %s)", gotReq, w.Code, w.Body.String())
+	}
+
+	gotReq = nil
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"signed_request": {signRequest("other-secret", payload)}}))
+	if gotReq != nil || w.Code != http.StatusBadRequest {
+		t.Fatalf("expected forged signed request to be rejected but got %d", w.Code)
+	}
+}
+
+func TestDeauthorizeHandlerWithoutSecret(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "app-id"}
+	payload := map[string]interface{}{
+		"algorithm": "HMAC-SHA256",
+		"issued_at": 1700000000,
+		"user_id":   "42",
+	}
+
+	if _, err := ParseSignedRequest(cfg, signRequest("", payload)); err == nil {
+		t.Fatal("expected signed request to be rejected without an app secret")
+	}
+
+	var called bool
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	w := httptest.NewRecorder()
+	NewDeauthorizeHandler(cfg, nil, terminal).ServeHTTP(w, postForm(url.Values{"signed_request": {signRequest("", payload)}}))
+	if called || w.Code != http.StatusBadRequest {
+		t.Fatalf("expected signed request to be rejected but got %d", w.Code)
+	}
+}
+
+func TestDataDeletionHandler(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "app-id", ClientSecret: "app-secret"}
+	fn := func(_ context.Context, req *SignedRequest) (string, string, error) {
+		return "https://example.com/deletion?id=" + req.UserID, "code-" + req.UserID, nil
+	}
+	h := NewDataDeletionHandler(cfg, fn, nil)
+
+	signed := signRequest("app-secret", map[string]interface{}{
+		"algorithm": "HMAC-SHA256",
+		"user_id":   "42",
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"signed_request": {signed}}))
+
+	var got struct {
+		URL              string `json:"url"`
+		ConfirmationCode string `json:"confirmation_code"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != "https://example.com/deletion?id=42" || got.ConfirmationCode != "code-42" {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
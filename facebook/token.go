@@ -0,0 +1,76 @@
+package facebook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	fb "github.com/huandu/facebook/v2"
+	"golang.org/x/oauth2"
+)
+
+// newLongLivedTokenHandler swaps the token set by the token handler for a long lived one before
+// calling the user handler.
+func newLongLivedTokenHandler(cfg *oauth2.Config, o *options, errHandler, userHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := autho2.TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		longLived, err := exchangeToken(r.Context(), cfg, tkn, o)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		tknCtx := autho2.ContextWithToken(r.Context(), longLived)
+		userHandler.ServeHTTP(w, r.WithContext(tknCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// exchangeToken exchanges the short lived user token tkn for a long lived one.
+func exchangeToken(ctx context.Context, cfg *oauth2.Config, tkn *oauth2.Token, o *options) (*oauth2.Token, error) {
+	session := newSession(cfg, "", oauth2.NewClient(ctx, nil), o)
+	res, err := session.WithContext(ctx).Get("/oauth/access_token", fb.Params{
+		"grant_type":        "fb_exchange_token",
+		"client_id":         cfg.ClientID,
+		"client_secret":     cfg.ClientSecret,
+		"fb_exchange_token": tkn.AccessToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := res.Decode(&info); err != nil {
+		return nil, err
+	}
+	if info.AccessToken == "" {
+		return nil, errors.New("autho: long lived token missing.")
+	}
+
+	longLived := &oauth2.Token{
+		AccessToken: info.AccessToken,
+		TokenType:   info.TokenType,
+	}
+	if info.ExpiresIn > 0 {
+		longLived.Expiry = time.Now().Add(time.Duration(info.ExpiresIn) * time.Second)
+	}
+
+	return longLived, nil
+}
@@ -0,0 +1,51 @@
+package facebook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho/internal/apitest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+func TestLongLivedTokenHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+DefaultVersion+"/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("grant_type") != "fb_exchange_token" || q.Get("fb_exchange_token") != "short-token" || q.Get("client_secret") != "app-secret" {
+			t.Errorf("unexpected exchange request: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "long-token",
+			"token_type":   "bearer",
+			"expires_in":   5183944,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{ClientID: "app-id", ClientSecret: "app-secret"}
+	o := newOptions([]Option{WithLongLivedToken()})
+
+	var got *oauth2.Token
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = autho2.TokenFromContext(r.Context())
+	})
+	h := newLongLivedTokenHandler(cfg, o, nil, next)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), &oauth2.Token{AccessToken: "short-token"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, apitest.Request(r, srv))
+
+	if got == nil || got.AccessToken != "long-token" {
+		t.Fatalf("expected long lived token but got %+v (%d: %s)", got, w.Code, w.Body.String())
+	}
+	if time.Until(got.Expiry) < 59*24*time.Hour {
+		t.Fatalf("expected long lived expiry but got %v", got.Expiry)
+	}
+}
@@ -0,0 +1,216 @@
+// Package generic implements configurable OAuth1 and OAuth2 providers which fetch the user
+// resource from a userinfo url and map its fields to a normalised User.
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Lambels/autho"
+)
+
+// Provider describes where to fetch the user resource from and how to map it to a User.
+//
+//	p := &generic.Provider{
+//		UserInfoURL: "https://api.example.com/me",
+//		Fields: generic.Fields{
+//			ID:        "/data/id",
+//			Email:     "data.email",
+//			AvatarURL: "data.images.0.url",
+//		},
+//	}
+type Provider struct {
+	// UserInfoURL is the url of the user resource.
+	UserInfoURL string
+	// Method is the http method used to request the user resource, defaults to GET.
+	Method string
+	// Header holds additional headers sent with the user resource request.
+	Header http.Header
+	// Envelope is the path of the user object in the response, used by providers which wrap
+	// the user resource (e.g. "response" for tumblr). The Fields paths are relative to it.
+	Envelope string
+	// RequestSecret indicates that the OAuth1 provider needs the request secret in the callback
+	// step, the request secret is then persisted in the cookie described by the cookie config
+	// which is required (see oauth1.WithRequestSecret()). A cookie config passed without the
+	// switch also enables it, as with the oauth1 handlers (deprecated). It is ignored by OAuth2
+	// providers.
+	RequestSecret bool
+	// Fields maps the user resource to the User.
+	Fields Fields
+}
+
+// Fields holds the paths of the User fields in the user resource.
+//
+// A path is either a JSON pointer (RFC 6901) such as "/data/id" or a dotted path such as
+// "data.id", array elements are addressed by their index ("emails.0.value"). Empty paths
+// are left unmapped, except ID which defaults to "id".
+type Fields struct {
+	ID    string
+	Email string
+	// EmailVerified is the path of a boolean flagging the email as verified.
+	EmailVerified string
+	Name          string
+	AvatarURL     string
+}
+
+// User represents the normalised user resource.
+type User struct {
+	ID            string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+	// Raw holds the whole decoded user resource.
+	Raw map[string]interface{}
+}
+
+// Identity implements autho.Identifier.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:            u.ID,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+	}
+}
+
+func (p *Provider) me(ctx context.Context, client *http.Client) (*User, error) {
+	method := p.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range p.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, autho.ErrNoUser
+	}
+
+	var data interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, autho.ErrNoUser
+	}
+
+	data, ok := lookup(data, p.Envelope)
+	if !ok {
+		return nil, autho.ErrNoUser
+	}
+
+	return p.Fields.user(data)
+}
+
+func (f Fields) user(data interface{}) (*User, error) {
+	raw, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, autho.ErrNoUser
+	}
+
+	idPath := f.ID
+	if idPath == "" {
+		idPath = "id"
+	}
+
+	user := &User{
+		ID:            lookupString(data, idPath),
+		Email:         lookupString(data, f.Email),
+		EmailVerified: lookupString(data, f.EmailVerified) == "true",
+		Name:          lookupString(data, f.Name),
+		AvatarURL:     lookupString(data, f.AvatarURL),
+		Raw:           raw,
+	}
+	if user.ID == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return user, nil
+}
+
+// lookup walks v following path.
+func lookup(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+
+	var segments []string
+	if strings.HasPrefix(path, "/") {
+		segments = strings.Split(path[1:], "/")
+		for i, seg := range segments {
+			seg = strings.ReplaceAll(seg, "~1", "/")
+			segments[i] = strings.ReplaceAll(seg, "~0", "~")
+		}
+	} else {
+		segments = strings.Split(path, ".")
+	}
+
+	for _, seg := range segments {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			v = next
+
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// lookupString returns the value found at path formatted as a string, empty paths and
+// missing or null values result in an empty string.
+func lookupString(v interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	val, ok := lookup(v, path)
+	if !ok || val == nil {
+		return ""
+	}
+
+	switch val := val.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+
+	// objects and arrays are kept in their json form.
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(val); err != nil {
+		return fmt.Sprint(val)
+	}
+	return strings.TrimSpace(buf.String())
+}
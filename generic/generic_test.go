@@ -0,0 +1,165 @@
+package generic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lambels/autho"
+	autho1 "github.com/Lambels/autho/oauth1"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"github.com/dghubble/oauth1"
+	"golang.org/x/oauth2"
+)
+
+func TestOAuth2UserHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			t.Errorf("expected bearer token but got %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Accept") != "application/vnd.example+json" {
+			t.Errorf("expected custom accept header but got %q", r.Header.Get("Accept"))
+		}
+		w.Write([]byte(`{"data":{"id":12345678901234567,"email":"user@example.com","full/name":"Jane","images":[{"url":"https://img.example.com/1"}]}}`))
+	}))
+	defer srv.Close()
+
+	p := &Provider{
+		UserInfoURL: srv.URL,
+		Header:      http.Header{"Accept": {"application/vnd.example+json"}},
+		Fields: Fields{
+			ID:        "/data/id",
+			Email:     "data.email",
+			Name:      "/data/full~1name",
+			AvatarURL: "data.images.0.url",
+		},
+	}
+
+	var gotUser *User
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+	})
+	h := NewOAuth2UserHandler(&oauth2.Config{}, p, nil, terminal)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), &oauth2.Token{AccessToken: "access-token"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if gotUser == nil {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+	expected := User{
+		ID:        "12345678901234567",
+		Email:     "user@example.com",
+		Name:      "Jane",
+		AvatarURL: "https://img.example.com/1",
+	}
+	if gotUser.ID != expected.ID || gotUser.Email != expected.Email || gotUser.Name != expected.Name || gotUser.AvatarURL != expected.AvatarURL {
+		t.Fatalf("expected user %+v but got %+v", expected, gotUser)
+	}
+}
+
+func TestOAuth1UserHandlerEnvelope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "OAuth ") {
+			t.Errorf("expected oauth1 authorization header but got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"meta":{"status":200,"msg":"OK"},"response":{"user":{"name":"jane"}}}`))
+	}))
+	defer srv.Close()
+
+	p := &Provider{
+		UserInfoURL: srv.URL,
+		Envelope:    "response",
+		Fields: Fields{
+			ID:   "user.name",
+			Name: "user.name",
+		},
+	}
+
+	var gotUser *User
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+	})
+	h := NewOAuth1UserHandler(oauth1.NewConfig("key", "secret"), p, nil, terminal)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho1.ContextWithToken(r.Context(), oauth1.NewToken("token", "secret")))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if gotUser == nil {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser.ID != "jane" {
+		t.Fatalf("expected user id: jane but got %s", gotUser.ID)
+	}
+}
+
+func TestOAuth1RequestSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("oauth_token=request-token&oauth_token_secret=request-secret&oauth_callback_confirmed=true"))
+	}))
+	defer srv.Close()
+
+	cfg := oauth1.NewConfig("key", "secret")
+	cfg.Endpoint = oauth1.Endpoint{
+		RequestTokenURL: srv.URL + "/request_token",
+		AuthorizeURL:    srv.URL + "/authorize",
+	}
+	ckCfg := autho.NewDebugCookieConfig("request_secret")
+
+	tests := map[string]struct {
+		requestSecret bool
+		ckCfg         *autho.CookieConfig
+		cookie        string
+	}{
+		"persisted":     {requestSecret: true, ckCfg: ckCfg, cookie: "request-secret"},
+		"implicit":      {ckCfg: ckCfg, cookie: "request-secret"},
+		"not persisted": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &Provider{RequestSecret: tc.requestSecret}
+
+			w := httptest.NewRecorder()
+			NewOAuth1LoginHandler(cfg, p, tc.ckCfg, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusFound {
+				t.Fatalf("expected redirect but got %d: %s", w.Code, w.Body.String())
+			}
+
+			var got string
+			for _, ck := range w.Result().Cookies() {
+				if ck.Name == ckCfg.Name {
+					got = ck.Value
+				}
+			}
+			if got != tc.cookie {
+				t.Fatalf("expected request secret cookie %q but got %q", tc.cookie, got)
+			}
+		})
+	}
+}
+
+func TestOAuth1RequestSecretWithoutCookieConfig(t *testing.T) {
+	p := &Provider{RequestSecret: true}
+
+	tests := map[string]func(){
+		"login handler": func() { NewOAuth1LoginHandler(oauth1.NewConfig("key", "secret"), p, nil, nil) },
+		"token handler": func() { NewOAuth1TokenHandler(oauth1.NewConfig("key", "secret"), p, nil, nil, nil) },
+	}
+
+	for name, construct := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected construction without cookie config to panic")
+				}
+			}()
+			construct()
+		})
+	}
+}
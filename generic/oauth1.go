@@ -0,0 +1,70 @@
+package generic
+
+import (
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho1 "github.com/Lambels/autho/oauth1"
+	"github.com/dghubble/oauth1"
+)
+
+// NewOAuth1CallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler generic.NewOAuth1TokenHandler()
+// wrapped around the default generic.NewOAuth1UserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewOAuth1CallbackHandler(cfg *oauth1.Config, p *Provider, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+	return NewOAuth1TokenHandler(
+		cfg,
+		p,
+		ckCfg,
+		errHandler,
+		NewOAuth1UserHandler(
+			cfg,
+			p,
+			errHandler,
+			terminalHandler,
+		),
+	)
+}
+
+// NewOAuth1LoginHandler creates a new LoginHandler which is responsible for requesting the
+// request token, if p.RequestSecret is set the login handler is also responsible for setting the
+// request secret in the ckCfg cookie for later reading in the callback step. Afterwards the login
+// handler is also responsible for redirecting the user to the provider.
+//
+// ckCfg is required if p.RequestSecret is set, see oauth1.WithRequestSecret(). A non nil ckCfg
+// enables the request secret regardless of p.RequestSecret (deprecated).
+func NewOAuth1LoginHandler(cfg *oauth1.Config, p *Provider, ckCfg *autho.CookieConfig, errHandler http.Handler) http.Handler {
+	return autho1.NewLoginHandler(cfg, ckCfg, errHandler, p.oauth1Options()...)
+}
+
+// NewOAuth1TokenHandler creates a new TokenHandler which is responsible for exchanging the
+// request token, request secret (if p.RequestSecret is set) and verifier for the access token
+// and access secret. Following the exchange the token handler is responsible to add the access
+// token under the request ctx, calling on success the userHandler.
+//
+// ckCfg is required if p.RequestSecret is set, see oauth1.WithRequestSecret(). A non nil ckCfg
+// enables the request secret regardless of p.RequestSecret (deprecated).
+func NewOAuth1TokenHandler(cfg *oauth1.Config, p *Provider, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho1.NewTokenHandler(cfg, ckCfg, errHandler, userHandler, p.oauth1Options()...)
+}
+
+// NewOAuth1UserHandler creates a new generic UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource found at p.UserInfoURL. The user resource
+// is unwrapped from p.Envelope, mapped following p.Fields and set under the request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*generic.User)
+func NewOAuth1UserHandler(cfg *oauth1.Config, p *Provider, errHandler, terminalHandler http.Handler) http.Handler {
+	return autho1.NewUserHandlerFunc(cfg, p.me, errHandler, terminalHandler)
+}
+
+// oauth1Options translates the p.RequestSecret switch to the options of the oauth1 handlers.
+func (p *Provider) oauth1Options() []autho1.Option {
+	if !p.RequestSecret {
+		return nil
+	}
+
+	return []autho1.Option{autho1.WithRequestSecret()}
+}
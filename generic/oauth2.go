@@ -0,0 +1,54 @@
+package generic
+
+import (
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewOAuth2CallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler generic.NewOAuth2TokenHandler()
+// wrapped around the default generic.NewOAuth2UserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewOAuth2CallbackHandler(cfg *oauth2.Config, p *Provider, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+	return NewOAuth2TokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewOAuth2UserHandler(
+			cfg,
+			p,
+			errHandler,
+			terminalHandler,
+		),
+	)
+}
+
+// NewOAuth2LoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+func NewOAuth2LoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg)
+}
+
+// NewOAuth2TokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+func NewOAuth2TokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+}
+
+// NewOAuth2UserHandler creates a new generic UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource found at p.UserInfoURL. The user resource
+// is mapped following p.Fields and set under the request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*generic.User)
+func NewOAuth2UserHandler(cfg *oauth2.Config, p *Provider, errHandler, terminalHandler http.Handler) http.Handler {
+	return autho2.NewUserHandlerFunc(cfg, p.me, errHandler, terminalHandler)
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+// refreshTokenExpiryKey is the token extra holding the absolute expiry of the refresh token.
+const refreshTokenExpiryKey string = "refresh_token_expiry"
+
+// tokenExtras are the token response fields kept when the token extras are rewritten.
+var tokenExtras []string = []string{"scope", "token_type", "expires_in", "refresh_token_expires_in"}
+
+// ErrRefreshTokenExpired represents the refresh token of a github app user token being expired,
+// the user must sign in again.
+var ErrRefreshTokenExpired error = errors.New("autho: github refresh token is expired")
+
+// RefreshTokenExpiry returns the expiry of the refresh token of a github app user token obtained
+// through the github handlers or github.NewTokenSource(). The zero time is returned for tokens
+// without an expiring refresh token.
+func RefreshTokenExpiry(tkn *oauth2.Token) time.Time {
+	switch v := tkn.Extra(refreshTokenExpiryKey).(type) {
+	case int64:
+		return time.Unix(v, 0)
+	case float64:
+		return time.Unix(int64(v), 0)
+	}
+
+	return time.Time{}
+}
+
+// withRefreshTokenExpiry converts the relative refresh_token_expires_in of the token response
+// to an absolute expiry, stored under the token extras.
+func withRefreshTokenExpiry(tkn *oauth2.Token, issued time.Time) *oauth2.Token {
+	var expiresIn int64
+	switch v := tkn.Extra("refresh_token_expires_in").(type) {
+	case string:
+		expiresIn, _ = strconv.ParseInt(v, 10, 64)
+	case int64:
+		expiresIn = v
+	case float64:
+		expiresIn = int64(v)
+	}
+	if expiresIn <= 0 {
+		return tkn
+	}
+
+	extra := map[string]interface{}{
+		refreshTokenExpiryKey: issued.Add(time.Duration(expiresIn) * time.Second).Unix(),
+	}
+	for _, key := range tokenExtras {
+		if v := tkn.Extra(key); v != nil && v != "" {
+			extra[key] = v
+		}
+	}
+
+	return tkn.WithExtra(extra)
+}
+
+// newRefreshTokenExpiryHandler records the refresh token expiry of the token set by the token
+// handler before calling the user handler.
+func newRefreshTokenExpiryHandler(errHandler, userHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := autho2.TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		tknCtx := autho2.ContextWithToken(r.Context(), withRefreshTokenExpiry(tkn, time.Now()))
+		userHandler.ServeHTTP(w, r.WithContext(tknCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewTokenSource creates a new token source for the github app user token tkn. Github app user
+// tokens expire after 8 hours and are refreshed with their refresh token, which expires after 6
+// months and is rotated on every refresh.
+//
+// onRefresh (optional) is called with every refreshed token so it can be persisted, the previous
+// refresh token is no longer valid afterwards. If onRefresh fails Token returns the refreshed
+// token alongside the wrapped error, the token source keeps using the refreshed token. Once the
+// refresh token expires Token returns github.ErrRefreshTokenExpired.
+func NewTokenSource(ctx context.Context, cfg *oauth2.Config, tkn *oauth2.Token, onRefresh func(*oauth2.Token) error, opts ...Option) oauth2.TokenSource {
+	return &tokenSource{
+		ctx:       ctx,
+		cfg:       configFor(cfg, newOptions(opts)),
+		tkn:       tkn,
+		onRefresh: onRefresh,
+		now:       time.Now,
+	}
+}
+
+type tokenSource struct {
+	ctx       context.Context
+	cfg       *oauth2.Config
+	onRefresh func(*oauth2.Token) error
+	now       func() time.Time
+
+	mu  sync.Mutex
+	tkn *oauth2.Token
+}
+
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tkn.Valid() {
+		return s.tkn, nil
+	}
+
+	now := s.now()
+	if expiry := RefreshTokenExpiry(s.tkn); !expiry.IsZero() && !now.Before(expiry) {
+		return nil, ErrRefreshTokenExpired
+	}
+	if s.tkn.RefreshToken == "" {
+		return nil, errors.New("autho: github token expired without a refresh token")
+	}
+
+	tkn, err := s.cfg.TokenSource(s.ctx, &oauth2.Token{RefreshToken: s.tkn.RefreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	// the previous refresh token is revoked by the exchange, keep the new one even if it can't
+	// be persisted.
+	tkn = withRefreshTokenExpiry(tkn, now)
+	s.tkn = tkn
+
+	if s.onRefresh != nil {
+		if err := s.onRefresh(tkn); err != nil {
+			return tkn, fmt.Errorf("autho: persisting refreshed github token: %w", err)
+		}
+	}
+
+	return tkn, nil
+}
+
+func listInstallationIDs(ctx context.Context, c *github.Client) ([]int64, error) {
+	ids := []int64{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.Apps.ListUserInstallations(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, installation := range page {
+			ids = append(ids, installation.GetID())
+		}
+
+		if resp.NextPage == 0 {
+			return ids, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
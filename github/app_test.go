@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh-1" {
+			t.Errorf("unexpected refresh request: %v", r.Form)
+		}
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=access-2&expires_in=28800&refresh_token=refresh-2&refresh_token_expires_in=15811200&token_type=bearer"))
+	}))
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID:     "Iv1.app",
+		ClientSecret: "secret",
+		Endpoint:     EnterpriseEndpoint(srv.URL),
+	}
+	expired := &oauth2.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+
+	var persisted *oauth2.Token
+	ts := NewTokenSource(context.Background(), cfg, expired, func(tkn *oauth2.Token) error {
+		persisted = tkn
+		return nil
+	})
+	tkn, err := ts.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tkn.AccessToken != "access-2" || tkn.RefreshToken != "refresh-2" {
+		t.Fatalf("expected rotated tokens but got %s / %s", tkn.AccessToken, tkn.RefreshToken)
+	}
+	if persisted != tkn {
+		t.Fatal("expected refreshed token to be passed to onRefresh")
+	}
+	if expiry := RefreshTokenExpiry(tkn); expiry.Before(time.Now().Add(180 * 24 * time.Hour)) {
+		t.Fatalf("expected refresh token expiry in ~6 months but got %s", expiry)
+	}
+}
+
+func TestTokenSourceRefreshTokenExpired(t *testing.T) {
+	expired := withRefreshTokenExpiry((&oauth2.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		Expiry:       time.Now().Add(-time.Minute),
+	}).WithExtra(map[string]interface{}{
+		"refresh_token_expires_in": float64(60),
+	}), time.Now().Add(-time.Hour))
+
+	ts := NewTokenSource(context.Background(), &oauth2.Config{}, expired, nil)
+	if _, err := ts.Token(); !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("expected ErrRefreshTokenExpired but got %v", err)
+	}
+}
+
+func TestTokenSourcePersistFailure(t *testing.T) {
+	var refreshes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=access-2&expires_in=28800&refresh_token=refresh-2&token_type=bearer"))
+	}))
+	defer srv.Close()
+
+	cfg := &oauth2.Config{ClientID: "Iv1.app", Endpoint: EnterpriseEndpoint(srv.URL)}
+	expired := &oauth2.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+
+	errPersist := errors.New("store unavailable")
+	ts := NewTokenSource(context.Background(), cfg, expired, func(*oauth2.Token) error {
+		return errPersist
+	})
+
+	tkn, err := ts.Token()
+	if !errors.Is(err, errPersist) {
+		t.Fatalf("expected persistence error but got %v", err)
+	}
+	if tkn == nil || tkn.RefreshToken != "refresh-2" {
+		t.Fatalf("expected refreshed token alongside the error but got %v", tkn)
+	}
+
+	// the rotated token is kept, the revoked refresh token isn't used again.
+	tkn, err = ts.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tkn.AccessToken != "access-2" || refreshes != 1 {
+		t.Fatalf("expected refreshed token to be reused but got %s after %d refreshes", tkn.AccessToken, refreshes)
+	}
+}
+
+func TestTokenHandlerRefreshTokenExpiry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=access-1&expires_in=28800&refresh_token=refresh-1&refresh_token_expires_in=15811200&token_type=bearer"))
+	}))
+	defer srv.Close()
+
+	var got *oauth2.Token
+	user := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = autho2.TokenFromContext(r.Context())
+	})
+	ckCfg := autho.NewDebugCookieConfig("state")
+	h := NewTokenHandler(&oauth2.Config{ClientID: "Iv1.app"}, ckCfg, nil, user, WithEnterprise(srv.URL))
+
+	r := httptest.NewRequest(http.MethodGet, "/callback?state=abc&code=code", nil)
+	r.AddCookie(&http.Cookie{Name: ckCfg.Name, Value: "abc"})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got == nil {
+		t.Fatalf("expected token in context but got %d: %s", w.Code, w.Body.String())
+	}
+	if expiry := RefreshTokenExpiry(got); expiry.Before(time.Now().Add(180 * 24 * time.Hour)) {
+		t.Fatalf("expected refresh token expiry in ~6 months but got %s", expiry)
+	}
+	if got.Extra("token_type") != "bearer" {
+		t.Fatalf("expected token response extras to be kept but got %v", got.Extra("token_type"))
+	}
+}
+
+func TestUserHandlerApp(t *testing.T) {
+	srv := newAPIServer(t, "", map[string]interface{}{
+		"/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+		},
+		"/user/installations": map[string]interface{}{
+			"total_count":   2,
+			"installations": []map[string]interface{}{{"id": 100}, {"id": 200}},
+		},
+	})
+
+	var (
+		got []int64
+		ok  bool
+	)
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = InstallationIDsFromContext(r.Context())
+	})
+	w := serveUserHandler(NewUserHandler(&oauth2.Config{}, nil, terminal, WithApp()), srv)
+
+	if !ok || len(got) != 2 || got[0] != 100 || got[1] != 200 {
+		t.Fatalf("expected installation ids [100 200] but got %v (%d: %s)", got, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+
+	"github.com/google/go-github/v32/github"
+)
+
+type extrasKey struct{}
+
+// extras holds the resources fetched alongside the user by the user handler.
+type extras struct {
+	emails *Emails
+	orgs   []*github.Organization
+	teams  []*github.Team
+
+	installations []int64
+}
+
+func contextWithExtras(ctx context.Context, ex *extras) context.Context {
+	return context.WithValue(ctx, extrasKey{}, ex)
+}
+
+// EmailsFromContext harvests the verified emails of the user from the request context, they
+// are set by the user handler when using the github.WithEmails() option.
+func EmailsFromContext(ctx context.Context) (*Emails, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.emails == nil {
+		return nil, false
+	}
+
+	return ex.emails, true
+}
+
+// OrganizationsFromContext harvests the organizations the user is a member of from the request
+// context, they are set by the user handler when using the github.WithMemberships() option.
+func OrganizationsFromContext(ctx context.Context) ([]*github.Organization, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.orgs == nil {
+		return nil, false
+	}
+
+	return ex.orgs, true
+}
+
+// TeamsFromContext harvests the teams the user is a member of from the request context, they
+// are set by the user handler when using the github.WithMemberships() option.
+func TeamsFromContext(ctx context.Context) ([]*github.Team, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.teams == nil {
+		return nil, false
+	}
+
+	return ex.teams, true
+}
+
+// InstallationIDsFromContext harvests the ids of the github app installations accessible to the
+// user from the request context, they are set by the user handler when using the
+// github.WithApp() option.
+func InstallationIDsFromContext(ctx context.Context) ([]int64, bool) {
+	ex, ok := ctx.Value(extrasKey{}).(*extras)
+	if !ok || ex.installations == nil {
+		return nil, false
+	}
+
+	return ex.installations, true
+}
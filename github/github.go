@@ -0,0 +1,286 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Lambels/autho"
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+// Emails holds the verified emails of the user.
+//
+// https://docs.github.com/en/rest/users/emails#list-email-addresses-for-the-authenticated-user
+type Emails struct {
+	// Primary is the verified primary email of the user, empty if the primary email isn't verified.
+	Primary string
+	// Verified holds all the verified emails of the user, including the primary one.
+	Verified []*github.UserEmail
+}
+
+// EnterpriseEndpoint returns the oauth2 endpoint of the GitHub Enterprise Server found at host.
+func EnterpriseEndpoint(host string) oauth2.Endpoint {
+	host = strings.TrimSuffix(host, "/")
+
+	return oauth2.Endpoint{
+		AuthURL:  host + "/login/oauth/authorize",
+		TokenURL: host + "/login/oauth/access_token",
+	}
+}
+
+// configFor returns cfg targeting the GitHub Enterprise Server host if one is configured.
+func configFor(cfg *oauth2.Config, o *options) *oauth2.Config {
+	if o.enterprise == "" {
+		return cfg
+	}
+
+	enterpriseCfg := *cfg
+	enterpriseCfg.Endpoint = EnterpriseEndpoint(o.enterprise)
+	return &enterpriseCfg
+}
+
+func newClient(client *http.Client, o *options) (*github.Client, error) {
+	var c *github.Client
+	if o.enterprise != "" {
+		var err error
+		c, err = github.NewEnterpriseClient(o.enterprise+"/api/v3/", o.enterprise+"/api/uploads/", client)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c = github.NewClient(client)
+	}
+
+	return c, nil
+}
+
+// me fetches the user alongside the resources enabled by the options.
+func me(ctx context.Context, client *http.Client, o *options) (*github.User, *extras, error) {
+	c, err := newClient(client, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the memberships and installations are looked up concurrently with the profile.
+	var (
+		wg               sync.WaitGroup
+		memberships      *memberships
+		membersErr       error
+		installations    []int64
+		installationsErr error
+	)
+	if o.memberships {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			memberships, membersErr = listMemberships(ctx, c)
+		}()
+	}
+	if o.app {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			installations, installationsErr = listInstallationIDs(ctx, c)
+		}()
+	}
+
+	user, resp, err := c.Users.Get(ctx, "")
+	wg.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, autho.ErrNoUser
+	}
+	if user == nil || user.ID == nil {
+		return nil, nil, autho.ErrNoUser
+	}
+	ex := &extras{}
+
+	if o.memberships {
+		if membersErr != nil {
+			return nil, nil, membersErr
+		}
+		if err := memberships.authorize(o); err != nil {
+			return nil, nil, err
+		}
+		ex.orgs = memberships.orgs
+		ex.teams = memberships.teams
+	}
+
+	if o.app {
+		if installationsErr != nil {
+			return nil, nil, installationsErr
+		}
+		ex.installations = installations
+	}
+
+	if o.emails && hasScope(resp, "user:email", "user") {
+		emails, err := listEmails(ctx, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		if user.GetEmail() == "" && emails.Primary != "" {
+			user.Email = github.String(emails.Primary)
+		}
+		ex.emails = emails
+	}
+
+	return user, ex, nil
+}
+
+type memberships struct {
+	orgs  []*github.Organization
+	teams []*github.Team
+}
+
+// listMemberships lists the organizations and teams of the user concurrently.
+func listMemberships(ctx context.Context, c *github.Client) (*memberships, error) {
+	var (
+		wg       sync.WaitGroup
+		m        memberships
+		teamsErr error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.teams, teamsErr = listTeams(ctx, c)
+	}()
+
+	orgs, err := listOrganizations(ctx, c)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if teamsErr != nil {
+		return nil, teamsErr
+	}
+	m.orgs = orgs
+
+	return &m, nil
+}
+
+// authorize checks the memberships against the required organizations and teams.
+func (m *memberships) authorize(o *options) error {
+	if len(o.orgs) > 0 {
+		var member bool
+		for _, org := range m.orgs {
+			if containsFold(o.orgs, org.GetLogin()) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return &autho.ErrUserNotAuthorized{Reason: "user is not a member of the required github organizations"}
+		}
+	}
+
+	if len(o.teams) > 0 {
+		var member bool
+		for _, team := range m.teams {
+			if containsFold(o.teams, team.GetOrganization().GetLogin()+"/"+team.GetSlug()) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return &autho.ErrUserNotAuthorized{Reason: "user is not a member of the required github teams"}
+		}
+	}
+
+	return nil
+}
+
+func listOrganizations(ctx context.Context, c *github.Client) ([]*github.Organization, error) {
+	orgs := []*github.Organization{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.Organizations.List(ctx, "", opts)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, page...)
+
+		if resp.NextPage == 0 {
+			return orgs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func listTeams(ctx context.Context, c *github.Client) ([]*github.Team, error) {
+	teams := []*github.Team{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.Teams.ListUserTeams(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, page...)
+
+		if resp.NextPage == 0 {
+			return teams, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func listEmails(ctx context.Context, c *github.Client) (*Emails, error) {
+	emails := &Emails{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.Users.ListEmails(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, email := range page {
+			if !email.GetVerified() {
+				continue
+			}
+			if email.GetPrimary() {
+				emails.Primary = email.GetEmail()
+			}
+			emails.Verified = append(emails.Verified, email)
+		}
+
+		if resp.NextPage == 0 {
+			return emails, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// hasScope reports if the token used for the request was granted one of scopes. Tokens without
+// scopes (github app user tokens) are governed by the app permissions instead and are assumed
+// to have the scope.
+func hasScope(resp *github.Response, scopes ...string) bool {
+	values, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !ok {
+		return true
+	}
+
+	for _, granted := range strings.Split(strings.Join(values, ","), ",") {
+		granted = strings.TrimSpace(granted)
+		for _, scope := range scopes {
+			if granted == scope {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsFold(set []string, s string) bool {
+	for _, v := range set {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
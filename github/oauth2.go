@@ -1,7 +1,9 @@
 package github
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/Lambels/autho"
 	autho2 "github.com/Lambels/autho/oauth2"
@@ -15,7 +17,7 @@ import (
 //
 // This method saves allot of boilerplate. For more customisable handlers construct your
 // own callback handler by wrapping your own specific token handler around your own specific user handler.
-func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
 	return NewTokenHandler(
 		cfg,
 		ckCfg,
@@ -24,15 +26,17 @@ func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandle
 			cfg,
 			errHandler,
 			terminalHandler,
+			opts...,
 		),
+		opts...,
 	)
 }
 
 // NewLoginHandler creates a new LoginHandler which is resposible for setting a random
 // value (state) to the state cookie. Afterwards the login handler is also
 // responsible for redirecting the user to the provider for the users grant.
-func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
-	return autho2.NewLoginHandler(cfg, ckCfg)
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...Option) http.Handler {
+	return autho2.NewLoginHandler(configFor(cfg, newOptions(opts)), ckCfg)
 }
 
 // NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
@@ -40,8 +44,15 @@ func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler
 // and state then comparing the cookie state with the request state. Following the parsing the
 // TokenHandler performs the token exchange and adds the token to the request context, calling on
 // success the UserHandler.
-func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, callbackHandler http.Handler) http.Handler {
-	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, callbackHandler)
+//
+// The expiry of github app refresh tokens is recorded on the token, see github.RefreshTokenExpiry().
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, callbackHandler http.Handler, opts ...Option) http.Handler {
+	return autho2.NewTokenHandler(
+		configFor(cfg, newOptions(opts)),
+		ckCfg,
+		errHandler,
+		newRefreshTokenExpiryHandler(errHandler, callbackHandler),
+	)
 }
 
 // NewUserHandler creates a new github UserHandler resposnible for using the tokens provided
@@ -50,40 +61,27 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 //
 //	user, ok := autho.UserFromContext(r.Context()).(*github.User)
 //
-// The UserModel used by default by the github.NewUserHandler is: https://pkg.go.dev/github.com/google/go-github/v45/github#User
-func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
-	}
+// The UserModel used by default by the github.NewUserHandler is: https://pkg.go.dev/github.com/google/go-github/v32/github#User
+//
+// Additional resources fetched alongside the user (see the github Options) are set under the
+// request context, e.g. github.EmailsFromContext().
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
 
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho2.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
+	fetch := func(ctx context.Context, client *http.Client) (*github.User, *extras, error) {
+		return me(ctx, client, o)
+	}
 
-		// create a client and validate response.
-		client := github.NewClient(
-			cfg.Client(r.Context(), tkn),
-		)
-		user, resp, err := client.Users.Get(r.Context(), "")
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
-		if resp.StatusCode != http.StatusOK {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
-		}
-		if user == nil || user.ID == nil {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
-		}
+	terminalHandler = autho.NewIdentityHandler(identity, terminalHandler)
+	return autho2.NewUserHandlerFuncWithExtras(configFor(cfg, o), fetch, contextWithExtras, errHandler, terminalHandler)
+}
 
-		userCtx := autho.ContextWithUser(r.Context(), user)
-		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+// identity describes the identity of the github user, github only allows verified emails to be
+// set as the public profile email.
+func identity(u *github.User) autho.Identity {
+	return autho.Identity{
+		ID:            strconv.FormatInt(u.GetID(), 10),
+		Email:         u.GetEmail(),
+		EmailVerified: u.GetEmail() != "",
 	}
-
-	return http.HandlerFunc(f)
 }
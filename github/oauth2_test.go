@@ -0,0 +1,205 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+// newAPIServer stubs the github api, routes holds the json responses by path.
+func newAPIServer(t *testing.T, scopes string, routes map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("X-OAuth-Scopes", scopes)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// serveUserHandler serves h with an access token in the request context, requests to the github
+// api are sent to srv.
+func serveUserHandler(h http.Handler, srv *httptest.Server) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(apitest.Context(r.Context(), srv), &oauth2.Token{AccessToken: "access-token"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestUserHandlerEmails(t *testing.T) {
+	srv := newAPIServer(t, "read:user, user:email", map[string]interface{}{
+		"/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+		},
+		"/user/emails": []map[string]interface{}{
+			{"email": "octocat@github.com", "primary": true, "verified": true},
+			{"email": "octo@example.com", "primary": false, "verified": true},
+			{"email": "unverified@example.com", "primary": false, "verified": false},
+		},
+	})
+
+	var (
+		gotUser   *github.User
+		gotEmails *Emails
+	)
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContextAs[*github.User](r.Context())
+		gotEmails, _ = EmailsFromContext(r.Context())
+	})
+	w := serveUserHandler(NewUserHandler(&oauth2.Config{}, nil, terminal, WithEmails()), srv)
+
+	if gotUser == nil || gotEmails == nil {
+		t.Fatalf("expected user and emails in context but got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser.GetEmail() != "octocat@github.com" {
+		t.Fatalf("expected primary email to be set on user but got %q", gotUser.GetEmail())
+	}
+	if gotEmails.Primary != "octocat@github.com" {
+		t.Fatalf("expected primary email octocat@github.com but got %q", gotEmails.Primary)
+	}
+	if len(gotEmails.Verified) != 2 {
+		t.Fatalf("expected 2 verified emails but got %d", len(gotEmails.Verified))
+	}
+}
+
+func TestUserHandlerEmailsWithoutScope(t *testing.T) {
+	// requests to /user/emails fail the test through the stub.
+	srv := newAPIServer(t, "read:user", map[string]interface{}{
+		"/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+		},
+	})
+
+	var called bool
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if _, ok := EmailsFromContext(r.Context()); ok {
+			t.Fatal("expected no emails without the user:email scope")
+		}
+	})
+	w := serveUserHandler(NewUserHandler(&oauth2.Config{}, nil, terminal, WithEmails()), srv)
+
+	if !called {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandlerMemberships(t *testing.T) {
+	routes := map[string]interface{}{
+		"/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+		},
+		"/user/orgs": []map[string]interface{}{
+			{"id": 10, "login": "Acme"},
+		},
+		"/user/teams": []map[string]interface{}{
+			{"id": 20, "slug": "platform", "organization": map[string]interface{}{"login": "acme"}},
+		},
+	}
+
+	tests := map[string]struct {
+		opts     []Option
+		accepted bool
+	}{
+		"member of org":      {opts: []Option{RequireOrganizations("acme")}, accepted: true},
+		"member of team":     {opts: []Option{RequireTeams("acme/platform")}, accepted: true},
+		"not member of org":  {opts: []Option{RequireOrganizations("other")}},
+		"not member of team": {opts: []Option{RequireOrganizations("acme"), RequireTeams("acme/security")}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newAPIServer(t, "read:org", routes)
+
+			var gotOrgs []*github.Organization
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotOrgs, _ = OrganizationsFromContext(r.Context())
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			serveUserHandler(NewUserHandler(&oauth2.Config{}, errHandler, terminal, tc.opts...), srv)
+
+			if tc.accepted && len(gotOrgs) != 1 {
+				t.Fatalf("expected user to be accepted with its orgs but got %v", gotErr)
+			}
+			var notAuthorized *autho.ErrUserNotAuthorized
+			if !tc.accepted && !errors.As(gotErr, &notAuthorized) {
+				t.Fatalf("expected ErrUserNotAuthorized but got %v", gotErr)
+			}
+		})
+	}
+}
+
+func TestEnterprise(t *testing.T) {
+	srv := newAPIServer(t, "read:user", map[string]interface{}{
+		"/api/v3/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+		},
+	})
+
+	var called bool
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	w := serveUserHandler(NewUserHandler(&oauth2.Config{}, nil, terminal, WithEnterprise(srv.URL+"/")), srv)
+	if !called {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+
+	login := NewLoginHandler(&oauth2.Config{ClientID: "id"}, autho.NewDebugCookieConfig("state"), WithEnterprise(srv.URL))
+	w = httptest.NewRecorder()
+	login.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Host != strings.TrimPrefix(srv.URL, "http://") || loc.Path != "/login/oauth/authorize" {
+		t.Fatalf("expected redirect to the enterprise host but got %s", loc)
+	}
+}
+
+func TestUserHandlerIdentity(t *testing.T) {
+	srv := newAPIServer(t, "read:user", map[string]interface{}{
+		"/user": map[string]interface{}{
+			"id":    1,
+			"login": "octocat",
+			"email": "octocat@example.com",
+		},
+	})
+
+	var called bool
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	authz := autho.NewAuthorizeHandler(autho.All(autho.EmailDomains("example.com"), autho.AllowIDs("1")), nil, terminal)
+	w := serveUserHandler(NewUserHandler(&oauth2.Config{}, nil, authz), srv)
+
+	if !called {
+		t.Fatalf("expected user to be identified and accepted but got %d: %s", w.Code, w.Body.String())
+	}
+}
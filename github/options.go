@@ -0,0 +1,85 @@
+package github
+
+import "strings"
+
+// Option configures the github handlers.
+type Option func(*options)
+
+type options struct {
+	emails      bool
+	memberships bool
+	orgs        []string
+	teams       []string
+	enterprise  string
+	app         bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithEmails fetches the verified emails of the user from the /user/emails endpoint, which
+// requires the user:email scope. The emails are accessible with github.EmailsFromContext() and
+// the verified primary email is set as the user email if the user keeps their email private.
+//
+// The emails aren't fetched if the token wasn't granted the user:email (or user) scope.
+func WithEmails() Option {
+	return func(o *options) {
+		o.emails = true
+	}
+}
+
+// WithMemberships fetches the organizations and teams the user is a member of, which requires
+// the read:org scope. They are accessible with github.OrganizationsFromContext() and
+// github.TeamsFromContext().
+func WithMemberships() Option {
+	return func(o *options) {
+		o.memberships = true
+	}
+}
+
+// RequireOrganizations restricts sign in to members of at least one of orgs (by login), other
+// users are passed to the error handler as an *autho.ErrUserNotAuthorized. It implies
+// github.WithMemberships().
+func RequireOrganizations(orgs ...string) Option {
+	return func(o *options) {
+		o.memberships = true
+		o.orgs = append(o.orgs, orgs...)
+	}
+}
+
+// RequireTeams restricts sign in to members of at least one of teams, formatted as
+// "org/team-slug", other users are passed to the error handler as an
+// *autho.ErrUserNotAuthorized. It implies github.WithMemberships().
+func RequireTeams(teams ...string) Option {
+	return func(o *options) {
+		o.memberships = true
+		o.teams = append(o.teams, teams...)
+	}
+}
+
+// WithEnterprise targets the GitHub Enterprise Server found at host (e.g.
+// "https://github.example.com"). The api client uses the host api and the oauth2 endpoints of
+// cfg are replaced with github.EnterpriseEndpoint(host), pass the option to all the github
+// handlers.
+func WithEnterprise(host string) Option {
+	return func(o *options) {
+		o.enterprise = strings.TrimSuffix(host, "/")
+	}
+}
+
+// WithApp flags cfg as the client credentials of a github app instead of an oauth app. The user
+// handler then fetches the ids of the app installations accessible to the user, accessible with
+// github.InstallationIDsFromContext().
+//
+// Github app user tokens expire, use github.NewTokenSource() to keep using them after the sign in.
+func WithApp() Option {
+	return func(o *options) {
+		o.app = true
+	}
+}
@@ -0,0 +1,189 @@
+// Package gitlab provides handlers for gitlab.com and self-managed gitlab instances.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+// DefaultBaseURL is the base url of gitlab.com.
+const DefaultBaseURL string = "https://gitlab.com"
+
+// User represents fields accessible on a gitlab account.
+//
+// https://docs.gitlab.com/ee/api/users.html#list-current-user
+type User struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	AvatarURL   string `json:"avatar_url"`
+	WebURL      string `json:"web_url"`
+	Email       string `json:"email"`
+	PublicEmail string `json:"public_email"`
+	// ConfirmedAt is set once the user confirmed their primary email.
+	ConfirmedAt string `json:"confirmed_at"`
+	CreatedAt   string `json:"created_at"`
+	IsAdmin     bool   `json:"is_admin"`
+	Bot         bool   `json:"bot"`
+
+	// Groups holds the full paths of the groups of the user, only set when using
+	// gitlab.WithGroups() or gitlab.RequireGroups().
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Identity implements autho.Identifier, the primary email is verified once confirmed.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:            fmt.Sprint(u.ID),
+		Email:         u.Email,
+		EmailVerified: u.Email != "" && u.ConfirmedAt != "",
+	}
+}
+
+// APIError represents an error returned by the gitlab api.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("autho: gitlab api error (%d): %s", e.Status, e.Message)
+}
+
+// InstanceEndpoint returns the oauth2 endpoint of the gitlab instance found at baseURL.
+func InstanceEndpoint(baseURL string) oauth2.Endpoint {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	return oauth2.Endpoint{
+		AuthURL:  baseURL + "/oauth/authorize",
+		TokenURL: baseURL + "/oauth/token",
+	}
+}
+
+// Endpoint is the gitlab.com oauth2 endpoint.
+var Endpoint oauth2.Endpoint = InstanceEndpoint(DefaultBaseURL)
+
+// configFor returns cfg targeting the self-managed instance if one is configured.
+func configFor(cfg *oauth2.Config, o *options) *oauth2.Config {
+	if o.baseURL == DefaultBaseURL {
+		return cfg
+	}
+
+	instanceCfg := *cfg
+	instanceCfg.Endpoint = InstanceEndpoint(o.baseURL)
+	return &instanceCfg
+}
+
+func me(ctx context.Context, client *http.Client, o *options) (*User, error) {
+	var user User
+	if _, err := get(ctx, client, o.baseURL+"/api/v4/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, autho.ErrNoUser
+	}
+
+	if o.groups {
+		groups, err := listGroups(ctx, client, o)
+		if err != nil {
+			return nil, err
+		}
+		user.Groups = groups
+
+		if err := authorize(groups, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// listGroups lists the full paths of the groups the user is a member of, including the
+// subgroups accessible through the membership of a parent group.
+func listGroups(ctx context.Context, client *http.Client, o *options) ([]string, error) {
+	var paths []string
+
+	page := "1"
+	for page != "" {
+		params := url.Values{
+			"min_access_level": {"10"},
+			"per_page":         {"100"},
+			"page":             {page},
+		}
+
+		var groups []struct {
+			FullPath string `json:"full_path"`
+		}
+		resp, err := get(ctx, client, o.baseURL+"/api/v4/groups?"+params.Encode(), &groups)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			paths = append(paths, group.FullPath)
+		}
+
+		page = resp.Header.Get("X-Next-Page")
+	}
+
+	return paths, nil
+}
+
+// authorize checks the groups of the user against the required groups.
+func authorize(groups []string, o *options) error {
+	if len(o.required) == 0 {
+		return nil
+	}
+
+	// the groups listed by gitlab include the subgroups inherited from a parent membership, a
+	// member of a subgroup only isn't a member of the parent group.
+	for _, group := range groups {
+		for _, required := range o.required {
+			if strings.EqualFold(group, required) {
+				return nil
+			}
+		}
+	}
+
+	return &autho.ErrUserNotAuthorized{Reason: "user is not a member of the required gitlab groups"}
+}
+
+// get fetches the gitlab api resource at u into v, error responses are returned as an *APIError.
+func get(ctx context.Context, client *http.Client, u string, v interface{}) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		apiErr := &APIError{Status: resp.StatusCode, Message: body.Error}
+		if body.Message != nil {
+			apiErr.Message = fmt.Sprint(body.Message)
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,126 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// newInstance fakes the user and groups endpoints of a self-managed gitlab instance, the
+// groups are served over two pages.
+func newInstance(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"message": "401 Unauthorized"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           42,
+			"username":     "jane",
+			"email":        "jane@example.com",
+			"confirmed_at": "2020-01-01T00:00:00Z",
+		})
+	})
+	mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			json.NewEncoder(w).Encode([]map[string]string{{"full_path": "oss"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]string{{"full_path": "acme/platform/infra"}})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func serveUserHandler(h http.Handler, accessToken string) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), &oauth2.Token{AccessToken: accessToken}))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestUserHandlerGroups(t *testing.T) {
+	srv := newInstance(t)
+	cfg := &oauth2.Config{ClientID: "client-id"}
+
+	tests := map[string]struct {
+		groups   []string
+		accepted bool
+	}{
+		"exact group":        {groups: []string{"OSS"}, accepted: true},
+		"exact subgroup":     {groups: []string{"other", "acme/platform/infra"}, accepted: true},
+		"parent of subgroup": {groups: []string{"acme"}},
+		"sibling prefix":     {groups: []string{"acme/plat"}},
+		"not a member":       {groups: []string{"initech"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotUser *User
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewUserHandler(cfg, errHandler, terminal, WithBaseURL(srv.URL), RequireGroups(tc.groups...))
+
+			serveUserHandler(h, "access-token")
+
+			if tc.accepted {
+				if gotUser == nil || len(gotUser.Groups) != 2 {
+					t.Fatalf("expected user with 2 groups but got %+v (%v)", gotUser, gotErr)
+				}
+				return
+			}
+			var notAuthorized *autho.ErrUserNotAuthorized
+			if gotUser != nil || !errors.As(gotErr, &notAuthorized) {
+				t.Fatalf("expected *autho.ErrUserNotAuthorized but got %v", gotErr)
+			}
+		})
+	}
+}
+
+func TestUserHandlerAPIError(t *testing.T) {
+	srv := newInstance(t)
+
+	var gotErr error
+	errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotErr = autho.ErrorFromContext(r.Context())
+	})
+	h := NewUserHandler(&oauth2.Config{}, errHandler, http.NotFoundHandler(), WithBaseURL(srv.URL))
+
+	serveUserHandler(h, "revoked-token")
+
+	var apiErr *APIError
+	if !errors.As(gotErr, &apiErr) || apiErr.Status != http.StatusUnauthorized || apiErr.Message != "401 Unauthorized" {
+		t.Fatalf("expected *APIError but got %v", gotErr)
+	}
+}
+
+func TestLoginHandlerBaseURL(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "client-id", Endpoint: Endpoint}
+	h := NewLoginHandler(cfg, autho.NewDebugCookieConfig("state"), WithBaseURL("https://gitlab.example.com/"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if loc.Host != "gitlab.example.com" || loc.Path != "/oauth/authorize" {
+		t.Fatalf("expected redirect to the instance but got %s", loc)
+	}
+}
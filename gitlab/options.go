@@ -0,0 +1,55 @@
+package gitlab
+
+import "strings"
+
+// Option configures the gitlab handlers.
+type Option func(*options)
+
+type options struct {
+	baseURL string
+	groups  bool
+	// required group paths.
+	required []string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		baseURL: DefaultBaseURL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithBaseURL sets the base url of a self-managed gitlab instance (e.g. https://gitlab.example.com),
+// the oauth2 endpoint and the api of the instance are used by the handlers.
+//
+// Default: gitlab.DefaultBaseURL
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithGroups fetches the full paths of the groups (and subgroups) the user is a member of, which
+// requires the read_api scope. They are set under User.Groups.
+func WithGroups() Option {
+	return func(o *options) {
+		o.groups = true
+	}
+}
+
+// RequireGroups restricts sign in to members of at least one of groups (by exact full path, e.g.
+// "acme/platform"). Members of a group inherit the membership of its subgroups, members of a
+// subgroup only aren't accepted for the parent group. Other users are passed to the error
+// handler as an *autho.ErrUserNotAuthorized. It implies gitlab.WithGroups().
+func RequireGroups(groups ...string) Option {
+	return func(o *options) {
+		o.groups = true
+		for _, group := range groups {
+			o.required = append(o.required, strings.Trim(group, "/"))
+		}
+	}
+}
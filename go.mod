@@ -3,15 +3,17 @@ module github.com/Lambels/autho
 go 1.18
 
 require (
+	github.com/dghubble/go-twitter v0.0.0-20220716041154-837915ec2f79
+	github.com/dghubble/oauth1 v0.7.1
 	github.com/google/go-github/v32 v32.1.0
+	github.com/huandu/facebook/v2 v2.5.6
 	golang.org/x/oauth2 v0.0.0-20220722155238-128564f6959c
+	google.golang.org/api v0.90.0
 )
 
 require (
 	cloud.google.com/go/compute v1.7.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.1.3 // indirect
-	github.com/dghubble/go-twitter v0.0.0-20220716041154-837915ec2f79 // indirect
-	github.com/dghubble/oauth1 v0.7.1 // indirect
 	github.com/dghubble/sling v1.4.0 // indirect
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
@@ -19,13 +21,11 @@ require (
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/googleapis/enterprise-certificate-proxy v0.1.0 // indirect
 	github.com/googleapis/gax-go/v2 v2.4.0 // indirect
-	github.com/huandu/facebook/v2 v2.5.6 // indirect
 	go.opencensus.io v0.23.0 // indirect
 	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa // indirect
 	golang.org/x/net v0.0.0-20220728211354-c7608f3a8462 // indirect
 	golang.org/x/sys v0.0.0-20220728004956-3c1f35247d10 // indirect
 	golang.org/x/text v0.3.7 // indirect
-	google.golang.org/api v0.90.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto v0.0.0-20220624142145-8cd45d7dbd1f // indirect
 	google.golang.org/grpc v1.47.0 // indirect
@@ -0,0 +1,196 @@
+package google
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+	googleOauth "google.golang.org/api/oauth2/v2"
+)
+
+// KeysURL is the JSON Web Key Set used by google to sign id tokens.
+const KeysURL string = "https://www.googleapis.com/oauth2/v3/certs"
+
+// Issuers are the issuers of google id tokens.
+var Issuers []string = []string{"https://accounts.google.com", "accounts.google.com"}
+
+var defaultKeySet oidc.KeySet = oidc.NewRemoteKeySet(KeysURL, nil)
+
+type claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+	Locale        string `json:"locale"`
+	HostedDomain  string `json:"hd"`
+}
+
+// NewIDTokenHandler creates a new handler which signs in users with a google id token obtained
+// by a native sdk (android, ios, ...) and posted under the "id_token" form value. The id token
+// signature, audience (cfg.ClientID) and issuer are verified before the user resource is set
+// under the request context, calling on success the terminalHandler.
+//
+// When using google.WithHostedDomains() users outside the hosted domains or with an unverified
+// email are passed to the errHandler as an *autho.ErrUserNotAuthorized.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*oauth2.Userinfo)
+//
+// The UserModel is the same as the one used by google.NewUserHandler: https://pkg.go.dev/google.golang.org/api/oauth2/v2#Userinfo
+func NewIDTokenHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	o := newOptions(opts)
+	verifier := newVerifier(cfg, o)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			autho.PassError(errors.New("autho: id token must be posted."), errHandler, w, r)
+			return
+		}
+
+		raw := r.PostFormValue("id_token")
+		if raw == "" {
+			autho.PassError(errors.New("autho: id token missing."), errHandler, w, r)
+			return
+		}
+
+		userInfo, err := userinfoFromIDToken(r.Context(), verifier, o, raw)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		userCtx := autho.ContextWithUser(r.Context(), userInfo)
+		userCtx = autho.ContextWithIdentity(userCtx, identity(userInfo))
+		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewOneTapHandler creates a new handler for the credential posted by Google One Tap and the
+// "Sign in with Google" button (data-login_uri). The g_csrf_token double submit cookie is
+// compared with the posted g_csrf_token, then the credential (id token) is verified the same
+// way as by google.NewIDTokenHandler() before the user resource is set under the request
+// context, calling on success the terminalHandler.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*oauth2.Userinfo)
+//
+// The UserModel is the same as the one used by google.NewUserHandler: https://pkg.go.dev/google.golang.org/api/oauth2/v2#Userinfo
+func NewOneTapHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	o := newOptions(opts)
+	verifier := newVerifier(cfg, o)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			autho.PassError(errors.New("autho: credential must be posted."), errHandler, w, r)
+			return
+		}
+
+		// validate the double submit csrf token.
+		ck, err := r.Cookie("g_csrf_token")
+		if err != nil {
+			autho.PassError(errors.New("autho: csrf cookie missing."), errHandler, w, r)
+			return
+		}
+		csrfToken := r.PostFormValue("g_csrf_token")
+		if csrfToken == "" || subtle.ConstantTimeCompare([]byte(csrfToken), []byte(ck.Value)) != 1 {
+			autho.PassError(errors.New("autho: csrf token mismatch."), errHandler, w, r)
+			return
+		}
+
+		raw := r.PostFormValue("credential")
+		if raw == "" {
+			autho.PassError(errors.New("autho: credential missing."), errHandler, w, r)
+			return
+		}
+
+		userInfo, err := userinfoFromIDToken(r.Context(), verifier, o, raw)
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		userCtx := autho.ContextWithUser(r.Context(), userInfo)
+		userCtx = autho.ContextWithIdentity(userCtx, identity(userInfo))
+		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+func newVerifier(cfg *oauth2.Config, o *options) *oidc.Verifier {
+	return oidc.NewVerifier(o.keySet, &oidc.Config{
+		ClientIDs: append([]string{cfg.ClientID}, o.audiences...),
+		Issuers:   Issuers,
+	})
+}
+
+// verifyTokenIDToken verifies the id token returned alongside the access token in the token
+// response.
+func verifyTokenIDToken(ctx context.Context, verifier *oidc.Verifier, tkn *oauth2.Token) (*claims, error) {
+	raw, ok := tkn.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("autho: id token missing from token response, request the openid scope.")
+	}
+
+	idTkn, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := idTkn.Claims(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// userinfoFromIDToken verifies the raw id token and its claims, returning the user model.
+func userinfoFromIDToken(ctx context.Context, verifier *oidc.Verifier, o *options, raw string) (*googleOauth.Userinfo, error) {
+	tkn, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := tkn.Claims(&c); err != nil {
+		return nil, err
+	}
+	if err := o.authorize(&c); err != nil {
+		return nil, err
+	}
+
+	return userinfoFromClaims(&c)
+}
+
+// userinfoFromClaims maps the id token claims to the same user model returned by the
+// userinfo endpoint.
+func userinfoFromClaims(c *claims) (*googleOauth.Userinfo, error) {
+	if c.Subject == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return &googleOauth.Userinfo{
+		Id:            c.Subject,
+		Email:         c.Email,
+		VerifiedEmail: &c.EmailVerified,
+		Name:          c.Name,
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+		Picture:       c.Picture,
+		Locale:        c.Locale,
+		Hd:            c.HostedDomain,
+	}, nil
+}
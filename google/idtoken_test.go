@@ -0,0 +1,123 @@
+package google
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/oidctest"
+	"golang.org/x/oauth2"
+	googleOauth "google.golang.org/api/oauth2/v2"
+)
+
+func TestIDTokenHandler(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "web-client"}
+
+	var gotUser *googleOauth.Userinfo
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*googleOauth.Userinfo)
+	})
+	h := NewIDTokenHandler(cfg, nil, terminal, WithKeySet(signer.KeySet()), WithAudiences("ios-client"))
+
+	idToken := signer.Sign(map[string]interface{}{
+		"iss":            "https://accounts.google.com",
+		"sub":            "1234",
+		"aud":            "ios-client",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          "user@example.com",
+		"email_verified": true,
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"id_token": {idToken}}))
+
+	if gotUser == nil {
+		t.Fatalf("expected terminal handler to be called but got %d: %s", w.Code, w.Body.String())
+	}
+	if gotUser.Id != "1234" || gotUser.Email != "user@example.com" || !*gotUser.VerifiedEmail {
+		t.Fatalf("unexpected user: %+v", gotUser)
+	}
+}
+
+func TestIDTokenHandlerRejectsAudience(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "web-client"}
+
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("terminal handler called with foreign id token")
+	})
+	h := NewIDTokenHandler(cfg, nil, terminal, WithKeySet(signer.KeySet()))
+
+	idToken := signer.Sign(map[string]interface{}{
+		"iss": "https://accounts.google.com",
+		"sub": "1234",
+		"aud": "someone-else",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, postForm(url.Values{"id_token": {idToken}}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status code 400 but got %d", w.Code)
+	}
+}
+
+func TestOneTapHandler(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "web-client"}
+
+	var gotUser *googleOauth.Userinfo
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*googleOauth.Userinfo)
+	})
+	h := NewOneTapHandler(cfg, nil, terminal, WithKeySet(signer.KeySet()))
+
+	credential := signer.Sign(map[string]interface{}{
+		"iss":            "https://accounts.google.com",
+		"sub":            "1234",
+		"aud":            "web-client",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          "user@example.com",
+		"email_verified": true,
+	})
+
+	tests := map[string]struct {
+		cookie   string
+		form     string
+		accepted bool
+	}{
+		"valid":          {cookie: "csrf", form: "csrf", accepted: true},
+		"mismatch":       {cookie: "csrf", form: "other"},
+		"missing cookie": {form: "csrf"},
+		"missing form":   {cookie: "csrf"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotUser = nil
+			r := postForm(url.Values{"credential": {credential}, "g_csrf_token": {tc.form}})
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "g_csrf_token", Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if tc.accepted && (gotUser == nil || gotUser.Id != "1234") {
+				t.Fatalf("expected user 1234 but got %+v (%d: %s)", gotUser, w.Code, w.Body.String())
+			}
+			if !tc.accepted && w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status code 400 but got %d", w.Code)
+			}
+		})
+	}
+}
+
+func postForm(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
@@ -1,10 +1,13 @@
 package google
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/Lambels/autho"
 	autho2 "github.com/Lambels/autho/oauth2"
+	"github.com/Lambels/autho/oidc"
 	"golang.org/x/oauth2"
 	googleOauth "google.golang.org/api/oauth2/v2"
 )
@@ -15,7 +18,7 @@ import (
 //
 // This method saves allot of boilerplate. For more customisable handlers construct your
 // own callback handler by wrapping your own specific token handler around your own specific user handler.
-func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
 	return NewTokenHandler(
 		cfg,
 		ckCfg,
@@ -24,15 +27,29 @@ func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandle
 			cfg,
 			errHandler,
 			terminalHandler,
+			opts...,
 		),
+		opts...,
 	)
 }
 
 // NewLoginHandler creates a new LoginHandler which is resposible for setting a random
 // value (state) to the state cookie. Afterwards the login handler is also
 // responsible for redirecting the user to the provider for the users grant.
-func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
-	return autho2.NewLoginHandler(cfg, ckCfg)
+//
+// When using google.WithHostedDomains() the hd parameter is sent to google to only offer
+// accounts of the hosted domain.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...Option) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg, newOptions(opts).authCodeOptions()...)
+}
+
+// NewUpgradeLoginHandler creates a new LoginHandler for incremental authorization, it requests
+// scopes on top of the scopes already granted to your app (include_granted_scopes=true).
+//
+// Pass google.WithUpgradeScopes(scopes...) to the callback handler so that the declined scopes
+// can be reported by google.ScopesFromContext().
+func NewUpgradeLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, scopes []string, opts ...Option) http.Handler {
+	return NewLoginHandler(cfg, ckCfg, append(opts, WithUpgradeScopes(scopes...))...)
 }
 
 // NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
@@ -40,8 +57,16 @@ func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler
 // and state then comparing the cookie state with the request state. Following the parsing the
 // TokenHandler performs the token exchange and adds the token to the request context, calling on
 // success the UserHandler.
-func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, callbackHandler http.Handler) http.Handler {
-	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, callbackHandler)
+//
+// The scopes granted and declined by the user are set under the request context, see
+// google.ScopesFromContext().
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, callbackHandler http.Handler, opts ...Option) http.Handler {
+	return autho2.NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		newScopesHandler(cfg, newOptions(opts), errHandler, callbackHandler),
+	)
 }
 
 // NewUserHandler creates a new google UserHandler resposnible for using the tokens provided
@@ -51,39 +76,70 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 //	user, ok := autho.UserFromContext(r.Context()).(*oauth2.Userinfo)
 //
 // The UserModel used by default by the google.NewUserHandler is: https://pkg.go.dev/google.golang.org/api/oauth2/v2#Userinfo
-func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
+//
+// When using google.WithHostedDomains() the id token returned alongside the access token is
+// verified and users outside the hosted domains or with an unverified email are passed to the
+// errHandler as an *autho.ErrUserNotAuthorized. The openid and email scopes are required.
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	// the id token is only verified to restrict the hosted domains.
+	var verifier *oidc.Verifier
+	if len(o.hostedDomains) > 0 {
+		verifier = newVerifier(cfg, o)
 	}
 
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho2.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
-
-		service, err := googleOauth.New(
-			cfg.Client(r.Context(), tkn),
-		)
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
+	fetch := func(ctx context.Context, client *http.Client) (*googleOauth.Userinfo, error) {
+		var c *claims
+		if verifier != nil {
+			tkn, err := autho2.TokenFromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if c, err = verifyTokenIDToken(ctx, verifier, tkn); err != nil {
+				return nil, err
+			}
+			if err := o.authorize(c); err != nil {
+				return nil, err
+			}
 		}
 
-		userInfo, err := service.Userinfo.Get().Do()
+		userInfo, err := me(ctx, client)
 		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
+			return nil, err
 		}
-		if userInfo.Id == "" {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
+		if c != nil && c.Subject != userInfo.Id {
+			return nil, errors.New("autho: id token and userinfo subject mismatch")
 		}
 
-		userCtx := autho.ContextWithUser(r.Context(), userInfo)
-		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+		return userInfo, nil
+	}
+
+	terminalHandler = autho.NewIdentityHandler(identity, terminalHandler)
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
+
+func me(ctx context.Context, client *http.Client) (*googleOauth.Userinfo, error) {
+	service, err := googleOauth.New(client)
+	if err != nil {
+		return nil, err
 	}
 
-	return http.HandlerFunc(f)
+	userInfo, err := service.Userinfo.Get().Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	if userInfo.Id == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return userInfo, nil
+}
+
+// identity describes the identity of the google user.
+func identity(u *googleOauth.Userinfo) autho.Identity {
+	return autho.Identity{
+		ID:            u.Id,
+		Email:         u.Email,
+		EmailVerified: u.VerifiedEmail != nil && *u.VerifiedEmail,
+	}
 }
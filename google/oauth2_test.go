@@ -0,0 +1,176 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	"github.com/Lambels/autho/internal/oidctest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+	googleOauth "google.golang.org/api/oauth2/v2"
+)
+
+// newUserinfoContext returns a context routing the userinfo requests to a stub.
+func newUserinfoContext(t *testing.T, body string) context.Context {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return apitest.Context(context.Background(), srv)
+}
+
+func serveUserHandler(ctx context.Context, h http.Handler, idToken string) {
+	tkn := (&oauth2.Token{AccessToken: "access-token"}).WithExtra(map[string]interface{}{
+		"id_token": idToken,
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(ctx, tkn))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestUserHandlerHostedDomain(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "web-client"}
+	idClaims := func(sub, hd string, verified bool) map[string]interface{} {
+		return map[string]interface{}{
+			"iss":            "https://accounts.google.com",
+			"sub":            sub,
+			"aud":            "web-client",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"email":          "jane@" + hd,
+			"email_verified": verified,
+			"hd":             hd,
+		}
+	}
+
+	tests := map[string]struct {
+		claims        map[string]interface{}
+		accepted      bool
+		notAuthorized bool
+	}{
+		"hosted domain":    {claims: idClaims("1234", "example.com", true), accepted: true},
+		"other domain":     {claims: idClaims("1234", "other.com", true), notAuthorized: true},
+		"unverified email": {claims: idClaims("1234", "example.com", false), notAuthorized: true},
+		"no hosted domain": {claims: idClaims("1234", "", true), notAuthorized: true},
+		"subject mismatch": {claims: idClaims("other", "example.com", true)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := newUserinfoContext(t, `{"id":"1234","email":"jane@example.com","verified_email":true,"hd":"example.com"}`)
+
+			var gotUser *googleOauth.Userinfo
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContextAs[*googleOauth.Userinfo](r.Context())
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewUserHandler(cfg, errHandler, terminal, WithKeySet(signer.KeySet()), WithHostedDomains("example.com"))
+			serveUserHandler(ctx, h, signer.Sign(tc.claims))
+
+			if tc.accepted != (gotUser != nil) {
+				t.Fatalf("expected accepted: %t but got error %v", tc.accepted, gotErr)
+			}
+			var notAuthorized *autho.ErrUserNotAuthorized
+			if !tc.accepted && errors.As(gotErr, &notAuthorized) != tc.notAuthorized {
+				t.Fatalf("expected ErrUserNotAuthorized: %t but got %v", tc.notAuthorized, gotErr)
+			}
+		})
+	}
+}
+
+func TestUserHandler(t *testing.T) {
+	ctx := newUserinfoContext(t, `{"id":"1234","email":"jane@gmail.com","verified_email":true}`)
+
+	var gotUser *googleOauth.Userinfo
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContextAs[*googleOauth.Userinfo](r.Context())
+	})
+	var gotErr error
+	errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotErr = autho.ErrorFromContext(r.Context())
+	})
+	// without hosted domains no client id is needed and the id token isn't checked.
+	h := NewUserHandler(&oauth2.Config{}, errHandler, terminal)
+	serveUserHandler(ctx, h, "")
+
+	if gotUser == nil || gotUser.Id != "1234" {
+		t.Fatalf("expected user but got %+v (%v)", gotUser, gotErr)
+	}
+}
+
+func TestLoginHandlerHostedDomain(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "web-client", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.google.com/o/oauth2/auth"}}
+	h := NewLoginHandler(cfg, autho.NewDebugCookieConfig("state"), WithHostedDomains("example.com"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hd := loc.Query().Get("hd"); hd != "example.com" {
+		t.Fatalf("expected hd: example.com but got %q", hd)
+	}
+}
+
+func TestUpgradeScopes(t *testing.T) {
+	cfg := &oauth2.Config{
+		ClientID: "web-client",
+		Scopes:   []string{"openid", "email"},
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.google.com/o/oauth2/auth"},
+	}
+	upgrade := []string{
+		"https://www.googleapis.com/auth/drive.file",
+		"https://www.googleapis.com/auth/calendar.readonly",
+	}
+
+	login := NewUpgradeLoginHandler(cfg, autho.NewDebugCookieConfig("state"), upgrade)
+	w := httptest.NewRecorder()
+	login.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Query().Get("include_granted_scopes") != "true" {
+		t.Fatal("expected include_granted_scopes=true")
+	}
+	if scope := loc.Query().Get("scope"); scope != strings.Join(upgrade, " ") {
+		t.Fatalf("expected upgrade scopes but got %q", scope)
+	}
+
+	var gotScopes *Scopes
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotScopes, _ = ScopesFromContext(r.Context())
+	})
+	tkn := (&oauth2.Token{AccessToken: "access-token"}).WithExtra(map[string]interface{}{
+		"scope": "openid https://www.googleapis.com/auth/userinfo.email https://www.googleapis.com/auth/drive.file",
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), tkn))
+	newScopesHandler(cfg, newOptions([]Option{WithUpgradeScopes(upgrade...)}), nil, next).ServeHTTP(httptest.NewRecorder(), r)
+
+	if gotScopes == nil {
+		t.Fatal("expected scopes in context")
+	}
+	if !gotScopes.Has("https://www.googleapis.com/auth/userinfo.email") || len(gotScopes.Granted) != 3 {
+		t.Fatalf("unexpected granted scopes: %v", gotScopes.Granted)
+	}
+	if len(gotScopes.Declined) != 1 || gotScopes.Declined[0] != upgrade[1] {
+		t.Fatalf("expected calendar scope to be declined but got %v", gotScopes.Declined)
+	}
+}
@@ -0,0 +1,109 @@
+package google
+
+import (
+	"strings"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Option configures the google handlers.
+type Option func(*options)
+
+type options struct {
+	keySet        oidc.KeySet
+	audiences     []string
+	hostedDomains []string
+	upgradeScopes []string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		keySet: defaultKeySet,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithKeySet sets the key set used to verify the signature of google id tokens.
+//
+// Defaults to googles JSON Web Key Set which is fetched and cached by max-age.
+func WithKeySet(keySet oidc.KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+	}
+}
+
+// WithAudiences adds client ids accepted as the audience of id tokens besides cfg.ClientID.
+// Use it to accept id tokens issued to your android or ios clients.
+func WithAudiences(clientIDs ...string) Option {
+	return func(o *options) {
+		o.audiences = append(o.audiences, clientIDs...)
+	}
+}
+
+// WithHostedDomains restricts sign in to google workspace accounts of one of domains. The login
+// handler sends the hd hint and the user verifies the id token hd claim and email_verified,
+// other users are passed to the error handler as an *autho.ErrUserNotAuthorized.
+//
+// Pass the option to both the login and callback handlers.
+func WithHostedDomains(domains ...string) Option {
+	return func(o *options) {
+		o.hostedDomains = append(o.hostedDomains, domains...)
+	}
+}
+
+// WithUpgradeScopes requests scopes on top of the scopes already granted to your app
+// (incremental authorization), see google.NewUpgradeLoginHandler().
+//
+// Pass the option to both the login and callback handlers.
+func WithUpgradeScopes(scopes ...string) Option {
+	return func(o *options) {
+		o.upgradeScopes = append(o.upgradeScopes, scopes...)
+	}
+}
+
+func (o *options) authCodeOptions() []oauth2.AuthCodeOption {
+	var opts []oauth2.AuthCodeOption
+
+	if len(o.upgradeScopes) > 0 {
+		opts = append(
+			opts,
+			oauth2.SetAuthURLParam("scope", strings.Join(o.upgradeScopes, " ")),
+			oauth2.SetAuthURLParam("include_granted_scopes", "true"),
+		)
+	}
+
+	switch len(o.hostedDomains) {
+	case 0:
+	case 1:
+		opts = append(opts, oauth2.SetAuthURLParam("hd", o.hostedDomains[0]))
+	default:
+		// any hosted domain, the claims are checked in the callback step.
+		opts = append(opts, oauth2.SetAuthURLParam("hd", "*"))
+	}
+
+	return opts
+}
+
+// authorize checks the id token claims against the hosted domains.
+func (o *options) authorize(c *claims) error {
+	if len(o.hostedDomains) == 0 {
+		return nil
+	}
+
+	if !c.EmailVerified {
+		return &autho.ErrUserNotAuthorized{Reason: "google email is not verified"}
+	}
+	for _, domain := range o.hostedDomains {
+		if strings.EqualFold(c.HostedDomain, domain) {
+			return nil
+		}
+	}
+
+	return &autho.ErrUserNotAuthorized{Reason: "google account is outside the hosted domains"}
+}
@@ -0,0 +1,92 @@
+package google
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// Scopes represents the outcome of the users grant.
+type Scopes struct {
+	// Granted holds all the scopes granted to the token, including the previously granted ones
+	// when using incremental authorization.
+	Granted []string
+	// Declined holds the requested scopes the user didn't grant.
+	Declined []string
+}
+
+// Has reports if scope was granted.
+func (s *Scopes) Has(scope string) bool {
+	for _, granted := range s.Granted {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type scopesKey struct{}
+
+// ScopesFromContext harvests the scopes granted and declined by the user from the request
+// context, they are set by the google token handler.
+func ScopesFromContext(ctx context.Context) (*Scopes, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).(*Scopes)
+	return scopes, ok
+}
+
+// newScopesHandler compares the scopes of the token set by the token handler with the requested
+// scopes, adding the outcome under the request context before calling the user handler.
+func newScopesHandler(cfg *oauth2.Config, o *options, errHandler, userHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	requested := cfg.Scopes
+	if len(o.upgradeScopes) > 0 {
+		requested = o.upgradeScopes
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := autho2.TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		granted := autho2.GrantedScopes(tkn)
+		if granted == nil {
+			// google didn't report the scopes.
+			userHandler.ServeHTTP(w, r)
+			return
+		}
+
+		scopes := &Scopes{
+			Granted: granted,
+		}
+		for _, scope := range requested {
+			if !scopes.Has(expandScope(scope)) && !scopes.Has(scope) {
+				scopes.Declined = append(scopes.Declined, scope)
+			}
+		}
+
+		scopesCtx := context.WithValue(r.Context(), scopesKey{}, scopes)
+		userHandler.ServeHTTP(w, r.WithContext(scopesCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// expandScope maps the short openid connect scopes to the scopes reported by google.
+func expandScope(scope string) string {
+	switch scope {
+	case "email":
+		return "https://www.googleapis.com/auth/userinfo.email"
+	case "profile":
+		return "https://www.googleapis.com/auth/userinfo.profile"
+	}
+
+	return scope
+}
@@ -0,0 +1,48 @@
+// Package apitest routes the provider api calls made by the handlers to a local test server.
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/dghubble/oauth1"
+	"golang.org/x/oauth2"
+)
+
+// Transport sends all requests to the Target server, keeping their path and query.
+type Transport struct {
+	Target *url.URL
+}
+
+// RoundTrip rewrites the scheme and host of r to the ones of the Target server.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.Target.Scheme
+	r.URL.Host = t.Target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// Client returns a client sending all requests to srv.
+func Client(srv *httptest.Server) *http.Client {
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &http.Client{Transport: &Transport{Target: target}}
+}
+
+// Context returns ctx with a client sending all requests to srv, used by the oauth1 and oauth2
+// handlers in place of http.DefaultClient.
+func Context(ctx context.Context, srv *httptest.Server) context.Context {
+	client := Client(srv)
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	return context.WithValue(ctx, oauth1.HTTPClient, client)
+}
+
+// Request returns r with its context routing all requests to srv.
+func Request(r *http.Request, srv *httptest.Server) *http.Request {
+	return r.WithContext(Context(r.Context(), srv))
+}
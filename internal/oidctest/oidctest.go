@@ -0,0 +1,76 @@
+// Package oidctest provides a local signer and key set to test id token verification offline.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/Lambels/autho/oidc"
+)
+
+// Signer signs id tokens with a freshly generated RS256 key.
+type Signer struct {
+	Kid string
+	key *rsa.PrivateKey
+}
+
+// NewSigner creates a new Signer identified by kid.
+func NewSigner(kid string) *Signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Signer{
+		Kid: kid,
+		key: key,
+	}
+}
+
+// KeySet returns a static key set holding the public key of the signer.
+func (s *Signer) KeySet() oidc.StaticKeySet {
+	return oidc.StaticKeySet{
+		s.Kid: &s.key.PublicKey,
+	}
+}
+
+// JWKS returns the public key of the signer as a JSON Web Key Set.
+func (s *Signer) JWKS() oidc.JSONWebKeySet {
+	return oidc.JSONWebKeySet{
+		Keys: []oidc.JSONWebKey{{
+			Kid: s.Kid,
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	}
+}
+
+// Sign encodes claims as the payload of a RS256 signed JWT.
+func (s *Signer) Sign(claims interface{}) string {
+	hdr, _ := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"kid": s.Kid,
+		"typ": "JWT",
+	})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
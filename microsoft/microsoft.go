@@ -0,0 +1,133 @@
+// Package microsoft provides handlers for the microsoft identity platform (Entra ID, formerly
+// Azure AD) supporting work, school and personal microsoft accounts.
+package microsoft
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Authorities accepted by microsoft.AuthorityEndpoint() besides tenant ids and domains.
+const (
+	// Common accepts work, school and personal microsoft accounts.
+	Common string = "common"
+	// Organizations accepts work and school accounts.
+	Organizations string = "organizations"
+	// Consumers accepts personal microsoft accounts.
+	Consumers string = "consumers"
+)
+
+// ConsumersTenantID is the tenant id of personal microsoft accounts.
+const ConsumersTenantID string = "9188040d-6c67-4c5b-b112-36a304b66dad"
+
+// IssuerTemplate is the issuer of microsoft id tokens, {tenantid} is replaced by the tenant id
+// of the user (tid claim).
+const IssuerTemplate string = "https://login.microsoftonline.com/{tenantid}/v2.0"
+
+// KeysURL is the JSON Web Key Set used by microsoft to sign id tokens of all tenants.
+const KeysURL string = "https://login.microsoftonline.com/common/discovery/v2.0/keys"
+
+// graphURL is the base url of the microsoft graph v1.0 api.
+const graphURL string = "https://graph.microsoft.com/v1.0"
+
+var defaultKeySet oidc.KeySet = oidc.NewRemoteKeySet(KeysURL, nil)
+
+// AuthorityEndpoint returns the oauth2 endpoint of the authority, either microsoft.Common,
+// microsoft.Organizations, microsoft.Consumers or a tenant id (or domain) for single tenant apps.
+func AuthorityEndpoint(authority string) oauth2.Endpoint {
+	base := "https://login.microsoftonline.com/" + authority + "/oauth2/v2.0"
+
+	return oauth2.Endpoint{
+		AuthURL:  base + "/authorize",
+		TokenURL: base + "/token",
+	}
+}
+
+// User represents the microsoft graph profile of the user.
+//
+// https://learn.microsoft.com/en-us/graph/api/resources/user
+type User struct {
+	// ID is the object id of the user in its tenant (oid claim), the 16 hex digit cid for
+	// personal accounts.
+	ID                string   `json:"id"`
+	DisplayName       string   `json:"displayName"`
+	GivenName         string   `json:"givenName"`
+	Surname           string   `json:"surname"`
+	Mail              string   `json:"mail"`
+	UserPrincipalName string   `json:"userPrincipalName"`
+	JobTitle          string   `json:"jobTitle"`
+	OfficeLocation    string   `json:"officeLocation"`
+	PreferredLanguage string   `json:"preferredLanguage"`
+	BusinessPhones    []string `json:"businessPhones"`
+	MobilePhone       string   `json:"mobilePhone"`
+
+	// TenantID is the tenant of the user (tid claim), microsoft.ConsumersTenantID for personal
+	// accounts.
+	TenantID string `json:"tenantId"`
+
+	// Photo and PhotoContentType are only set when using microsoft.WithPhoto() and the user has
+	// a profile photo.
+	Photo            []byte `json:"photo,omitempty"`
+	PhotoContentType string `json:"photoContentType,omitempty"`
+}
+
+// Identity implements autho.Identifier. The email is reported as unverified, microsoft doesn't
+// guarantee the ownership of the mail attribute which is editable by tenant admins.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:    u.ID,
+		Email: u.Mail,
+	}
+}
+
+type claims struct {
+	Subject           string `json:"sub"`
+	ObjectID          string `json:"oid"`
+	TenantID          string `json:"tid"`
+	Email             string `json:"email"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+func newVerifier(cfg *oauth2.Config, o *options) *oidc.Verifier {
+	return oidc.NewVerifier(o.keySet, &oidc.Config{
+		ClientIDs: []string{cfg.ClientID},
+		// the issuer depends on the tenant of the user, checked against the tid claim.
+		SkipIssuerCheck: true,
+	})
+}
+
+// verifyTokenIDToken verifies the id token returned alongside the access token in the token
+// response, including its templated issuer and the allowed tenants.
+func verifyTokenIDToken(ctx context.Context, verifier *oidc.Verifier, o *options, tkn *oauth2.Token) (*claims, error) {
+	raw, ok := tkn.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("autho: id token missing from token response, request the openid scope.")
+	}
+
+	idTkn, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := idTkn.Claims(&c); err != nil {
+		return nil, err
+	}
+	if c.TenantID == "" {
+		return nil, errors.New("autho: id token tenant missing")
+	}
+	if idTkn.Issuer != strings.Replace(IssuerTemplate, "{tenantid}", c.TenantID, 1) {
+		return nil, errors.New("autho: id token issuer doesn't match its tenant")
+	}
+	if err := o.authorize(&c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,193 @@
+package microsoft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler microsoft.NewTokenHandler()
+// wrapped around the default microsoft.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+//
+// cfg.Endpoint is usually microsoft.AuthorityEndpoint() and cfg.Scopes must include "openid".
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg)
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+}
+
+// NewUserHandler creates a new microsoft UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The id token of the token response is
+// verified first, its issuer must match the tenant of the user (the issuer of multi tenant apps
+// is templated by tenant) and the tenant must be allowed by microsoft.WithTenants(). Following
+// the verification the profile of the user is fetched from the microsoft graph /me endpoint and
+// set under the request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*microsoft.User)
+//
+// The UserModel used by default by the microsoft.NewUserHandler is: https://learn.microsoft.com/en-us/graph/api/resources/user
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	verifier := newVerifier(cfg, o)
+
+	fetch := func(ctx context.Context, client *http.Client) (*User, error) {
+		tkn, err := autho2.TokenFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c, err := verifyTokenIDToken(ctx, verifier, o, tkn)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err := me(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		if c.ObjectID != "" && !sameObject(c.TenantID, c.ObjectID, user.ID) {
+			return nil, errors.New("autho: id token and graph user mismatch")
+		}
+		user.TenantID = c.TenantID
+
+		if o.photo {
+			if user.Photo, user.PhotoContentType, err = photo(ctx, client); err != nil {
+				return nil, err
+			}
+		}
+
+		return user, nil
+	}
+
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
+
+// sameObject reports if the oid claim of the id token and the graph user id identify the same
+// user. The graph id of personal accounts is their 16 hex digit cid while the oid claim is the
+// cid padded to a guid (00000000-0000-0000-<cid>).
+func sameObject(tenantID, oid, id string) bool {
+	if tenantID != ConsumersTenantID {
+		return oid == id
+	}
+
+	normalize := func(s string) string {
+		return strings.TrimLeft(strings.ToLower(strings.ReplaceAll(s, "-", "")), "0")
+	}
+	return normalize(oid) == normalize(id)
+}
+
+// GraphError represents an error returned by the microsoft graph api.
+//
+// https://learn.microsoft.com/en-us/graph/errors
+type GraphError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *GraphError) Error() string {
+	return fmt.Sprintf("autho: microsoft graph error (%d): %s: %s", e.Status, e.Code, e.Message)
+}
+
+func graphError(resp *http.Response) error {
+	var body struct {
+		Error *GraphError `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == nil {
+		return &GraphError{Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+	body.Error.Status = resp.StatusCode
+
+	return body.Error
+}
+
+func me(ctx context.Context, client *http.Client) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, graphURL+"/me", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, graphError(resp)
+	}
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+	if user.ID == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return &user, nil
+}
+
+// photo downloads the profile photo of the user, users without a photo return no error.
+func photo(ctx context.Context, client *http.Client) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, graphURL+"/me/photo/$value", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", nil
+	default:
+		return nil, "", graphError(resp)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b, resp.Header.Get("Content-Type"), nil
+}
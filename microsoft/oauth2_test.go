@@ -0,0 +1,127 @@
+package microsoft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	"github.com/Lambels/autho/internal/oidctest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+const tenantID string = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+
+// newGraphServer stubs the microsoft graph, id is the graph id of the user.
+func newGraphServer(t *testing.T, id string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.0/me", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":                id,
+			"displayName":       "Jane Doe",
+			"mail":              "jane@contoso.com",
+			"userPrincipalName": "jane@contoso.com",
+		})
+	})
+	mux.HandleFunc("/v1.0/me/photo/$value", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("jpeg"))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func serveUserHandler(h http.Handler, srv *httptest.Server, idToken string) *httptest.ResponseRecorder {
+	tkn := (&oauth2.Token{AccessToken: "access-token"}).WithExtra(map[string]interface{}{
+		"id_token": idToken,
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), tkn))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, apitest.Request(r, srv))
+	return w
+}
+
+func TestUserHandler(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "client-id", Endpoint: AuthorityEndpoint(Common)}
+	idClaims := func(tid, iss string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss": iss,
+			"sub": "pairwise-sub",
+			"oid": "00000000-0000-0000-0123-456789abcdef",
+			"tid": tid,
+			"aud": "client-id",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	orgIssuer := "https://login.microsoftonline.com/" + tenantID + "/v2.0"
+	consumersIssuer := "https://login.microsoftonline.com/" + ConsumersTenantID + "/v2.0"
+
+	tests := map[string]struct {
+		claims        map[string]interface{}
+		graphID       string
+		accepted      bool
+		notAuthorized bool
+	}{
+		"allowed tenant":    {claims: idClaims(tenantID, orgIssuer), graphID: "00000000-0000-0000-0123-456789abcdef", accepted: true},
+		"personal account":  {claims: idClaims(ConsumersTenantID, consumersIssuer), graphID: "0123456789ABCDEF", accepted: true},
+		"object mismatch":   {claims: idClaims(tenantID, orgIssuer), graphID: "0123456789abcdef"},
+		"personal mismatch": {claims: idClaims(ConsumersTenantID, consumersIssuer), graphID: "fedcba9876543210"},
+		"other tenant":      {claims: idClaims("other-tenant", "https://login.microsoftonline.com/other-tenant/v2.0"), notAuthorized: true},
+		"issuer mismatch":   {claims: idClaims(tenantID, "https://login.microsoftonline.com/other-tenant/v2.0")},
+		"missing tenant":    {claims: idClaims("", "https://login.microsoftonline.com//v2.0")},
+		"unexpected issuer": {claims: idClaims(tenantID, "https://evil.example.com/"+tenantID+"/v2.0")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			graphID := tc.graphID
+			if graphID == "" {
+				graphID = "00000000-0000-0000-0123-456789abcdef"
+			}
+			srv := newGraphServer(t, graphID)
+
+			var gotUser *User
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewUserHandler(cfg, errHandler, terminal, WithKeySet(signer.KeySet()), WithTenants(tenantID, ConsumersTenantID), WithPhoto())
+
+			serveUserHandler(h, srv, signer.Sign(tc.claims))
+
+			if tc.accepted {
+				if gotUser == nil || gotUser.ID != graphID || gotUser.TenantID != tc.claims["tid"] {
+					t.Fatalf("expected user to be accepted but got %+v (%v)", gotUser, gotErr)
+				}
+				if !bytes.Equal(gotUser.Photo, []byte("jpeg")) || gotUser.PhotoContentType != "image/jpeg" {
+					t.Fatalf("expected photo but got %q (%s)", gotUser.Photo, gotUser.PhotoContentType)
+				}
+				return
+			}
+
+			if gotUser != nil {
+				t.Fatal("expected user to be rejected")
+			}
+			var notAuthorized *autho.ErrUserNotAuthorized
+			if errors.As(gotErr, &notAuthorized) != tc.notAuthorized {
+				t.Fatalf("unexpected error: %v", gotErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package microsoft
+
+import (
+	"strings"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+)
+
+// Option configures the microsoft handlers.
+type Option func(*options)
+
+type options struct {
+	keySet  oidc.KeySet
+	tenants []string
+	photo   bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		keySet: defaultKeySet,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithKeySet sets the key set used to verify the signature of microsoft id tokens.
+//
+// Defaults to microsofts JSON Web Key Set which is fetched and cached by max-age.
+func WithKeySet(keySet oidc.KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+	}
+}
+
+// WithTenants restricts sign in to users of one of the tenant ids, other users are passed to the
+// error handler as an *autho.ErrUserNotAuthorized. Use microsoft.ConsumersTenantID to accept
+// personal microsoft accounts.
+//
+// Multi tenant apps (microsoft.Common, microsoft.Organizations) accept users of any tenant by
+// default.
+func WithTenants(tenantIDs ...string) Option {
+	return func(o *options) {
+		o.tenants = append(o.tenants, tenantIDs...)
+	}
+}
+
+// WithPhoto downloads the profile photo of the user alongside the profile, it is set under
+// User.Photo. Requires the User.Read scope.
+func WithPhoto() Option {
+	return func(o *options) {
+		o.photo = true
+	}
+}
+
+// authorize checks the id token tenant against the allowed tenants.
+func (o *options) authorize(c *claims) error {
+	if len(o.tenants) == 0 {
+		return nil
+	}
+
+	for _, tenant := range o.tenants {
+		if strings.EqualFold(c.TenantID, tenant) {
+			return nil
+		}
+	}
+
+	return &autho.ErrUserNotAuthorized{Reason: "microsoft tenant is not allowed"}
+}
@@ -1,6 +1,7 @@
 package oauth1
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Lambels/autho"
@@ -9,15 +10,20 @@ import (
 
 // NewLoginHandler creates a new LoginHandler which is responsible for requesting the
 // request token, if the provider requires to keep the request secret on the callback step
-// the login handler is also responsible for setting the request secret in a cookie for later
-// reading in the callback step. Afterwards the login handler is also responsible for redirecting
-// the user to the provider.
+// (oauth1.WithRequestSecret()) the login handler is also responsible for setting the request
+// secret in the ckCfg cookie for later reading in the callback step. Afterwards the login handler
+// is also responsible for redirecting the user to the provider.
+//
+// ckCfg is required with oauth1.WithRequestSecret(), a non nil ckCfg without the option still
+// enables the request secret (deprecated).
 //
 // LoginHandler -> Provider (obtain grant)
-func NewLoginHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler http.Handler) http.Handler {
+func NewLoginHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler http.Handler, opts ...Option) http.Handler {
 	if errHandler == nil {
 		errHandler = autho.DefaultFailureHandle
 	}
+	o := newOptions(opts)
+	o.checkCookieConfig(ckCfg)
 
 	f := func(w http.ResponseWriter, r *http.Request) {
 		reqToken, reqSecret, err := cfg.RequestToken()
@@ -26,9 +32,8 @@ func NewLoginHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler h
 			return
 		}
 
-		// if a cookie config is provided, it flags that the provider needs the req secret
-		// in the callback step, add it to a cookie.
-		if ckCfg != nil {
+		// the provider needs the req secret in the callback step, add it to a cookie.
+		if o.requestSecret {
 			ck := autho.GetCookie(ckCfg, r)
 			ck.Value = reqSecret
 			http.SetCookie(w, ck)
@@ -47,20 +52,23 @@ func NewLoginHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler h
 }
 
 // NewTokenHandler creates a new TokenHandler which is responsible for exchanging the
-// request token, request secret (if ckCfg != nil) and verifier for the access token and
-// access secret. Following the exchange the token handler is responsible to add the access token
-// under the request ctx, calling on success the userHandler.
+// request token, request secret (if using oauth1.WithRequestSecret()) and verifier for the access
+// token and access secret. Following the exchange the token handler is responsible to add the
+// access token under the request ctx, calling on success the userHandler.
 //
-// If ckCfg is provider (!= nil) then the provider needs also the request token for the
-// exchange. The upstream handler is required (in the redirect phase) to add the request
-// secret to the cookie. Read the request secret from the cookie and pass it to the exchange
-// else pass an empty string to the exchange.
+// With oauth1.WithRequestSecret() the provider needs also the request secret for the exchange.
+// The upstream handler is required (in the redirect phase) to add the request secret to the
+// ckCfg cookie. Read the request secret from the cookie and pass it to the exchange else pass an
+// empty string to the exchange. ckCfg is required with oauth1.WithRequestSecret(), a non nil
+// ckCfg without the option still enables the request secret (deprecated).
 //
 // Provider -> TokenHandler -> UserHandler -> TerminalHandler
-func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler, opts ...Option) http.Handler {
 	if errHandler == nil {
 		errHandler = autho.DefaultFailureHandle
 	}
+	o := newOptions(opts)
+	o.checkCookieConfig(ckCfg)
 
 	f := func(w http.ResponseWriter, r *http.Request) {
 		reqToken, verifier, err := oauth1.ParseAuthorizationCallback(r)
@@ -69,9 +77,9 @@ func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler,
 			return
 		}
 
-		// set request secret if ckCfg isnt nill.
+		// set request secret if the provider needs it.
 		var reqSecret string
-		if ckCfg != nil {
+		if o.requestSecret {
 			ck, err := r.Cookie(ckCfg.Name)
 			if err != nil {
 				autho.PassError(err, errHandler, w, r)
@@ -93,3 +101,47 @@ func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler,
 
 	return http.HandlerFunc(f)
 }
+
+// NewUserHandlerFunc creates a new UserHandler which uses the tokens provided by the TokenHandler
+// to create an authorized http client, fetch is then called with the client in exchange for the
+// users resource. The user resource is set under the request context, calling on success the
+// terminalHandler.
+//
+// Errors returned by fetch are passed to the errHandler wrapped in an *autho.UserError
+// (autho.ErrNoUser and *autho.ErrUserNotAuthorized are passed as is).
+//
+//	func me(ctx context.Context, client *http.Client) (*User, error) { ... }
+//
+//	userHandler := oauth1.NewUserHandlerFunc(cfg, me, errHandler, terminalHandler)
+func NewUserHandlerFunc[T any](cfg *oauth1.Config, fetch func(ctx context.Context, client *http.Client) (T, error), errHandler, terminalHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		user, err := fetch(r.Context(), cfg.Client(r.Context(), tkn))
+		if err != nil {
+			autho.PassError(wrapUserError(err), errHandler, w, r)
+			return
+		}
+
+		userCtx := autho.ContextWithUser(r.Context(), user)
+		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+func wrapUserError(err error) error {
+	if _, ok := err.(*autho.ErrUserNotAuthorized); ok || err == autho.ErrNoUser {
+		return err
+	}
+
+	return &autho.UserError{Err: err}
+}
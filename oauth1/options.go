@@ -0,0 +1,45 @@
+package oauth1
+
+import "github.com/Lambels/autho"
+
+// Option configures the oauth1 login and token handlers.
+type Option func(*options)
+
+type options struct {
+	requestSecret bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithRequestSecret flags that the provider needs the request secret in the callback step. The
+// login handler persists the request secret in the cookie described by the cookie config and
+// the token handler reads it back for the exchange.
+//
+// The cookie config is then required, the handlers panic when constructed without one. Pass the
+// option to both handlers.
+//
+// A cookie config passed without the option implicitly enables the request secret, this
+// behaviour is kept for compatibility and is deprecated: pass the option explicitly.
+func WithRequestSecret() Option {
+	return func(o *options) {
+		o.requestSecret = true
+	}
+}
+
+// checkCookieConfig applies ckCfg to the request secret switch, a cookie config enables the
+// request secret implicitly. Panics if the switch is set without a cookie config.
+func (o *options) checkCookieConfig(ckCfg *autho.CookieConfig) {
+	if o.requestSecret && ckCfg == nil {
+		panic("autho: oauth1.WithRequestSecret() requires a cookie config")
+	}
+	if ckCfg != nil {
+		o.requestSecret = true
+	}
+}
@@ -1,10 +1,12 @@
 package oauth2
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/Lambels/autho"
 	"golang.org/x/oauth2"
@@ -14,24 +16,31 @@ import (
 // value (state) to the state cookie. Afterwards the login handler is also
 // responsible for redirecting the user to the provider for the users grant.
 //
+// Any opts are added to the authorization url, e.g. provider specific parameters.
+//
 // LoginHandler -> Provider (obtain grant)
-func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.HandlerFunc {
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...oauth2.AuthCodeOption) http.HandlerFunc {
+	return newLoginHandler(cfg, ckCfg, func(w http.ResponseWriter, r *http.Request) []oauth2.AuthCodeOption {
+		return opts
+	})
+}
+
+// newLoginHandler creates the login handler, authOpts provides the options added to the
+// authorization url of each request.
+func newLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, authOpts func(w http.ResponseWriter, r *http.Request) []oauth2.AuthCodeOption) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// get any existing cookie or create a new one.
 		ck := autho.GetCookie(ckCfg, r)
 
 		// generate random state.
-		buf := make([]byte, 32)
-		rand.Read(buf)
-		dst := make([]byte, 32)
-		base64.RawURLEncoding.Encode(dst, buf)
-		ck.Value = string(dst)
+		state := randomString()
+		ck.Value = state
 
 		// set state.
 		http.SetCookie(w, ck)
 
 		// redirect to provider url.
-		redirectURL := cfg.AuthCodeURL(string(dst))
+		redirectURL := cfg.AuthCodeURL(state, authOpts(w, r)...)
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 	}
 }
@@ -44,6 +53,19 @@ func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler
 //
 // Provider -> TokenHandler -> UserHandler -> TermnialHandler
 func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return newTokenHandler(cfg, ckCfg, nil, errHandler, userHandler)
+}
+
+// NewTokenHandlerWithOptions creates a new TokenHandler like oauth2.NewTokenHandler(),
+// exchangeOpts (optional) provides per request options for the token exchange request, e.g. a
+// client secret generated per request.
+func NewTokenHandlerWithOptions(cfg *oauth2.Config, ckCfg *autho.CookieConfig, exchangeOpts func(r *http.Request) ([]oauth2.AuthCodeOption, error), errHandler, userHandler http.Handler) http.Handler {
+	return newTokenHandler(cfg, ckCfg, exchangeOpts, errHandler, userHandler)
+}
+
+// newTokenHandler creates the token handler, exchangeOpts (optional) provides extra options
+// for the token exchange request.
+func newTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, exchangeOpts func(r *http.Request) ([]oauth2.AuthCodeOption, error), errHandler, userHandler http.Handler) http.Handler {
 	if errHandler == nil {
 		errHandler = autho.DefaultFailureHandle
 	}
@@ -75,8 +97,16 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 			return
 		}
 
+		var opts []oauth2.AuthCodeOption
+		if exchangeOpts != nil {
+			if opts, err = exchangeOpts(r); err != nil {
+				autho.PassError(err, errHandler, w, r)
+				return
+			}
+		}
+
 		// exchange auth code for token.
-		tkn, err := cfg.Exchange(r.Context(), authCode)
+		tkn, err := cfg.Exchange(r.Context(), authCode, opts...)
 		if err != nil {
 			autho.PassError(err, errHandler, w, r)
 			return
@@ -89,3 +119,88 @@ func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler,
 
 	return http.HandlerFunc(fn)
 }
+
+// NewUserHandlerFunc creates a new UserHandler which uses the tokens provided by the TokenHandler
+// to create an authorized http client, fetch is then called with the client in exchange for the
+// users resource. The user resource is set under the request context, calling on success the
+// terminalHandler.
+//
+// Errors returned by fetch are passed to the errHandler wrapped in an *autho.UserError
+// (autho.ErrNoUser and *autho.ErrUserNotAuthorized are passed as is).
+//
+//	func me(ctx context.Context, client *http.Client) (*User, error) { ... }
+//
+//	userHandler := oauth2.NewUserHandlerFunc(cfg, me, errHandler, terminalHandler)
+func NewUserHandlerFunc[T any](cfg *oauth2.Config, fetch func(ctx context.Context, client *http.Client) (T, error), errHandler, terminalHandler http.Handler) http.Handler {
+	fetchExtras := func(ctx context.Context, client *http.Client) (T, struct{}, error) {
+		user, err := fetch(ctx, client)
+		return user, struct{}{}, err
+	}
+
+	return NewUserHandlerFuncWithExtras(cfg, fetchExtras, nil, errHandler, terminalHandler)
+}
+
+// NewUserHandlerFuncWithExtras creates a new UserHandler like oauth2.NewUserHandlerFunc() for
+// providers fetching additional resources alongside the users resource. fetch returns them as
+// extras which are set under the request context by withExtras (optional) after the user.
+//
+//	func me(ctx context.Context, client *http.Client) (*User, *Extras, error) { ... }
+//	func contextWithExtras(ctx context.Context, ex *Extras) context.Context { ... }
+//
+//	userHandler := oauth2.NewUserHandlerFuncWithExtras(cfg, me, contextWithExtras, errHandler, terminalHandler)
+func NewUserHandlerFuncWithExtras[T, E any](cfg *oauth2.Config, fetch func(ctx context.Context, client *http.Client) (T, E, error), withExtras func(ctx context.Context, extras E) context.Context, errHandler, terminalHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		user, extras, err := fetch(r.Context(), cfg.Client(r.Context(), tkn))
+		if err != nil {
+			autho.PassError(wrapUserError(err), errHandler, w, r)
+			return
+		}
+
+		userCtx := autho.ContextWithUser(r.Context(), user)
+		if withExtras != nil {
+			userCtx = withExtras(userCtx, extras)
+		}
+		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// randomString returns 32 random bytes encoded as unpadded base64 url.
+func randomString() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func wrapUserError(err error) error {
+	if _, ok := err.(*autho.ErrUserNotAuthorized); ok || err == autho.ErrNoUser {
+		return err
+	}
+
+	return &autho.UserError{Err: err}
+}
+
+// GrantedScopes returns the scopes granted to tkn as reported by the scope field of the token
+// response, providers separate the scopes by spaces or commas. Nil is returned if the provider
+// didn't report the scopes.
+func GrantedScopes(tkn *oauth2.Token) []string {
+	raw, ok := tkn.Extra("scope").(string)
+	if !ok {
+		return nil
+	}
+
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
@@ -0,0 +1,77 @@
+package oauth2
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+func TestLoginHandlerState(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "client-id", Endpoint: oauth2.Endpoint{AuthURL: "https://provider.example.com/authorize"}}
+	ckCfg := autho.NewDebugCookieConfig("state")
+	h := NewLoginHandler(cfg, ckCfg)
+
+	states := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected redirect but got %d", w.Code)
+		}
+		loc, err := url.Parse(w.Header().Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state := loc.Query().Get("state")
+
+		cks := w.Result().Cookies()
+		if len(cks) != 1 || cks[0].Name != ckCfg.Name || cks[0].Value != state {
+			t.Fatalf("expected state cookie %q but got %v", state, cks)
+		}
+		buf, err := base64.RawURLEncoding.DecodeString(state)
+		if err != nil || len(buf) != 32 {
+			t.Fatalf("expected 32 random bytes encoded in the state but got %q (%v)", state, err)
+		}
+		states[state] = true
+	}
+
+	if len(states) != 2 {
+		t.Fatal("expected a new state for each login")
+	}
+}
+
+func TestGrantedScopes(t *testing.T) {
+	tests := map[string]struct {
+		extra  map[string]interface{}
+		scopes []string
+	}{
+		"space separated": {extra: map[string]interface{}{"scope": "openid email profile"}, scopes: []string{"openid", "email", "profile"}},
+		"comma separated": {extra: map[string]interface{}{"scope": "read_orders,write_products"}, scopes: []string{"read_orders", "write_products"}},
+		"mixed":           {extra: map[string]interface{}{"scope": "openid, email"}, scopes: []string{"openid", "email"}},
+		"not reported":    {extra: map[string]interface{}{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := GrantedScopes((&oauth2.Token{AccessToken: "access-token"}).WithExtra(tc.extra))
+
+			if len(got) != len(tc.scopes) {
+				t.Fatalf("expected scopes %v but got %v", tc.scopes, got)
+			}
+			for i := range got {
+				if got[i] != tc.scopes[i] {
+					t.Fatalf("expected scopes %v but got %v", tc.scopes, got)
+				}
+			}
+			if tc.scopes == nil && got != nil {
+				t.Fatalf("expected nil scopes but got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+// verifierCookieSuffix is appended to the state cookie name to name the code verifier cookie.
+const verifierCookieSuffix string = "_verifier"
+
+// NewPKCELoginHandler creates a new LoginHandler for the authorization code flow with PKCE
+// (Proof Key for Code Exchange). Alongside the state cookie the login handler generates a code
+// verifier, persisted in a cookie named after ckCfg.Name with the "_verifier" suffix, and sends
+// its S256 code challenge to the provider.
+//
+// Use it with oauth2.NewPKCETokenHandler().
+//
+// https://www.rfc-editor.org/rfc/rfc7636
+func NewPKCELoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...oauth2.AuthCodeOption) http.HandlerFunc {
+	// clip opts so appending the code challenge always copies, opts is shared by all requests.
+	opts = opts[:len(opts):len(opts)]
+	verifierCkCfg := verifierCookieConfig(ckCfg)
+
+	authOpts := func(w http.ResponseWriter, r *http.Request) []oauth2.AuthCodeOption {
+		verifier := randomString()
+
+		ck := autho.GetCookie(verifierCkCfg, r)
+		ck.Value = verifier
+		http.SetCookie(w, ck)
+
+		return append(opts,
+			oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+	}
+
+	return newLoginHandler(cfg, ckCfg, authOpts)
+}
+
+// NewPKCETokenHandler creates a new TokenHandler for the authorization code flow with PKCE, it
+// behaves like oauth2.NewTokenHandler() but sends the code verifier set by the
+// oauth2.NewPKCELoginHandler() with the token exchange.
+func NewPKCETokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	exchangeOpts := func(r *http.Request) ([]oauth2.AuthCodeOption, error) {
+		ck, err := r.Cookie(verifierCookieConfig(ckCfg).Name)
+		if err != nil || ck.Value == "" {
+			return nil, errors.New("autho: code verifier missing.")
+		}
+
+		return []oauth2.AuthCodeOption{
+			oauth2.SetAuthURLParam("code_verifier", ck.Value),
+		}, nil
+	}
+
+	return newTokenHandler(cfg, ckCfg, exchangeOpts, errHandler, userHandler)
+}
+
+// verifierCookieConfig derives the code verifier cookie config from the state cookie config.
+func verifierCookieConfig(ckCfg *autho.CookieConfig) *autho.CookieConfig {
+	cfg := *ckCfg
+	cfg.Name += verifierCookieSuffix
+	return &cfg
+}
+
+// codeChallenge returns the S256 code challenge of verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+func TestPKCE(t *testing.T) {
+	var gotVerifier string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotVerifier = r.PostForm.Get("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "bearer",
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "client-id",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   srv.URL + "/authorize",
+			TokenURL:  srv.URL + "/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+	ckCfg := autho.NewDebugCookieConfig("state")
+	login := NewPKCELoginHandler(cfg, ckCfg, oauth2.AccessTypeOffline)
+
+	// login phase, the shared options must not collect the challenges of previous logins.
+	var cks []*http.Cookie
+	var loc *url.URL
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		login.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+		cks = w.Result().Cookies()
+		loc, _ = url.Parse(w.Header().Get("Location"))
+	}
+	query := loc.Query()
+	if len(query["code_challenge"]) != 1 || query.Get("code_challenge_method") != "S256" || query.Get("access_type") != "offline" {
+		t.Fatalf("unexpected authorization url: %s", loc)
+	}
+
+	var stateCk, verifierCk *http.Cookie
+	for _, ck := range cks {
+		switch ck.Name {
+		case "state":
+			stateCk = ck
+		case "state" + verifierCookieSuffix:
+			verifierCk = ck
+		}
+	}
+	if stateCk == nil || verifierCk == nil {
+		t.Fatalf("expected state and verifier cookies but got %v", cks)
+	}
+	if codeChallenge(verifierCk.Value) != query.Get("code_challenge") {
+		t.Fatal("expected the code challenge of the verifier cookie")
+	}
+
+	// callback phase.
+	tests := map[string]struct {
+		cookies  []*http.Cookie
+		accepted bool
+	}{
+		"verifier":         {cookies: []*http.Cookie{stateCk, verifierCk}, accepted: true},
+		"missing verifier": {cookies: []*http.Cookie{stateCk}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotVerifier = ""
+
+			var gotTkn *oauth2.Token
+			userHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTkn, _ = TokenFromContext(r.Context())
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewPKCETokenHandler(cfg, ckCfg, errHandler, userHandler)
+
+			r := httptest.NewRequest(http.MethodGet, "/callback?code=auth-code&state="+stateCk.Value, nil)
+			for _, ck := range tc.cookies {
+				r.AddCookie(ck)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !tc.accepted {
+				if gotTkn != nil || gotErr == nil || gotVerifier != "" {
+					t.Fatalf("expected the exchange to be rejected but got %v (%v)", gotTkn, gotErr)
+				}
+				return
+			}
+			if gotTkn == nil || gotTkn.AccessToken != "access-token" {
+				t.Fatalf("expected token but got %v (%v)", gotTkn, gotErr)
+			}
+			if gotVerifier != verifierCk.Value {
+				t.Fatalf("expected code verifier %q but got %q", verifierCk.Value, gotVerifier)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is used when the key set response doesn't specify a max-age.
+const defaultCacheTTL time.Duration = time.Hour
+
+// minRefreshInterval limits how often an unknown key id can trigger a refetch.
+const minRefreshInterval time.Duration = time.Minute
+
+// ErrKeyNotFound represents the key set not holding a key with the requested key id.
+var ErrKeyNotFound error = errors.New("autho: signing key not found in key set")
+
+// KeySet provides the public keys used to verify the signature of id tokens.
+type KeySet interface {
+	// Key returns the public key identified by kid.
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JSONWebKey represents a public key in the JSON Web Key format.
+//
+// https://www.rfc-editor.org/rfc/rfc7517
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// RSA public key parameters.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC public key parameters.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet represents a set of JSON Web Keys.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKey parses the JSON Web Key into a *rsa.PublicKey or *ecdsa.PublicKey.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("autho: unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("autho: unsupported key type %q", k.Kty)
+}
+
+// StaticKeySet is a KeySet holding a fixed set of keys, mostly useful for testing.
+type StaticKeySet map[string]crypto.PublicKey
+
+// Key returns the public key identified by kid.
+func (s StaticKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// RemoteKeySet is a KeySet which fetches the keys from a JSON Web Key Set URL. The keys are
+// cached for the max-age advertised by the provider (default: 1h) and refetched when they
+// expire or when a token is signed with an unknown key id (key rotation).
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	expiry    time.Time
+	lastFetch time.Time
+}
+
+// NewRemoteKeySet creates a new RemoteKeySet for the JSON Web Key Set found at url. If client
+// is nil the http.DefaultClient is used.
+func NewRemoteKeySet(url string, client *http.Client) *RemoteKeySet {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RemoteKeySet{
+		url:    url,
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Key returns the public key identified by kid, fetching the key set if needed.
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if key, ok := s.keys[kid]; ok && now.Before(s.expiry) {
+		return key, nil
+	}
+
+	// don't hammer the provider with requests for unknown key ids.
+	if s.keys != nil && now.Before(s.expiry) && now.Sub(s.lastFetch) < minRefreshInterval {
+		return nil, ErrKeyNotFound
+	}
+
+	if err := s.fetch(ctx, now); err != nil {
+		return nil, err
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("autho: fetching key set: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		// skip encryption keys and key types we don't understand.
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	s.keys = keys
+	s.lastFetch = now
+	s.expiry = now.Add(cacheTTL(resp.Header))
+	return nil
+}
+
+func cacheTTL(h http.Header) time.Duration {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+
+		secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+		if err != nil || secs <= 0 {
+			break
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	return defaultCacheTTL
+}
@@ -0,0 +1,256 @@
+// Package oidc verifies OpenID Connect id tokens (JWTs) against a provider's JSON Web Key Set.
+//
+// It is used by the providers which identify the user by an id token (google, apple, ...)
+// instead of, or in addition to, a userinfo endpoint.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrTokenExpired represents the id token being past its expiry.
+var ErrTokenExpired error = errors.New("autho: id token is expired")
+
+// ErrInvalidSignature represents the id token signature not matching the signing key.
+var ErrInvalidSignature error = errors.New("autho: id token signature is invalid")
+
+// ErrNoClientID represents a Verifier configured without any client id, it rejects all tokens.
+var ErrNoClientID error = errors.New("autho: id token verifier has no client id")
+
+// Config configures the checks performed by the Verifier on the id token claims.
+type Config struct {
+	// ClientIDs are the accepted audiences (aud) of the id token, usually the oauth2 client id.
+	ClientIDs []string
+	// Issuers are the accepted issuers (iss) of the id token.
+	Issuers []string
+	// SkipIssuerCheck disables the issuer check, the caller is then responsible to validate
+	// the issuer. Used by providers with templated issuers (e.g. per tenant).
+	SkipIssuerCheck bool
+	// Now is used to validate the expiry of the token, defaults to time.Now.
+	Now func() time.Time
+}
+
+// Verifier verifies the signature and standard claims of id tokens.
+type Verifier struct {
+	keySet KeySet
+	cfg    Config
+}
+
+// NewVerifier creates a new Verifier using keySet to look up the signing keys.
+//
+// Empty client ids are ignored so tokens with an empty audience are never accepted, a Verifier
+// left without client ids fails every Verify call with ErrNoClientID.
+func NewVerifier(keySet KeySet, cfg *Config) *Verifier {
+	v := &Verifier{
+		keySet: keySet,
+	}
+	if cfg != nil {
+		v.cfg = *cfg
+	}
+
+	clientIDs := make([]string, 0, len(v.cfg.ClientIDs))
+	for _, id := range v.cfg.ClientIDs {
+		if id != "" {
+			clientIDs = append(clientIDs, id)
+		}
+	}
+	v.cfg.ClientIDs = clientIDs
+	if v.cfg.Now == nil {
+		v.cfg.Now = time.Now
+	}
+
+	return v
+}
+
+// IDToken represents a verified id token.
+type IDToken struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+	Expiry   time.Time
+	IssuedAt time.Time
+	Nonce    string
+
+	claims []byte
+}
+
+// Claims unmarshals the raw claims of the id token into v.
+func (t *IDToken) Claims(v interface{}) error {
+	return json.Unmarshal(t.claims, v)
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type standardClaims struct {
+	Issuer   string   `json:"iss"`
+	Subject  string   `json:"sub"`
+	Audience audience `json:"aud"`
+	Expiry   int64    `json:"exp"`
+	IssuedAt int64    `json:"iat"`
+	Nonce    string   `json:"nonce"`
+}
+
+// audience represents the aud claim which is either a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+// Verify parses raw, verifies its signature and validates the expiry, audience and issuer
+// claims.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*IDToken, error) {
+	if len(v.cfg.ClientIDs) == 0 {
+		return nil, ErrNoClientID
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("autho: malformed id token")
+	}
+
+	var hdr header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("autho: malformed id token header: %w", err)
+	}
+
+	key, err := v.keySet.Key(ctx, hdr.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("autho: malformed id token signature: %w", err)
+	}
+	if err := verifySignature(hdr.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("autho: malformed id token payload: %w", err)
+	}
+	var claims standardClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("autho: malformed id token payload: %w", err)
+	}
+
+	tkn := &IDToken{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Audience: claims.Audience,
+		Expiry:   time.Unix(claims.Expiry, 0),
+		IssuedAt: time.Unix(claims.IssuedAt, 0),
+		Nonce:    claims.Nonce,
+		claims:   payload,
+	}
+
+	if !v.cfg.Now().Before(tkn.Expiry) {
+		return nil, ErrTokenExpired
+	}
+	if !v.cfg.SkipIssuerCheck && !contains(v.cfg.Issuers, tkn.Issuer) {
+		return nil, fmt.Errorf("autho: id token issued by unexpected issuer %q", tkn.Issuer)
+	}
+	if !containsAny(v.cfg.ClientIDs, tkn.Audience) {
+		return nil, errors.New("autho: id token issued for unexpected audience")
+	}
+
+	return tkn, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("autho: unsupported id token algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'R' {
+			return ErrInvalidSignature
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, sig); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if alg[0] != 'E' || len(sig) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	return fmt.Errorf("autho: unsupported signing key type %T", key)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func contains(set []string, s string) bool {
+	for _, v := range set {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(set []string, ss []string) bool {
+	for _, s := range ss {
+		if contains(set, s) {
+			return true
+		}
+	}
+
+	return false
+}
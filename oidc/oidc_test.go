@@ -0,0 +1,140 @@
+package oidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho/internal/oidctest"
+	"github.com/Lambels/autho/oidc"
+)
+
+func TestVerify(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	verifier := oidc.NewVerifier(signer.KeySet(), &oidc.Config{
+		ClientIDs: []string{"client-id"},
+		Issuers:   []string{"https://issuer.example.com"},
+	})
+
+	claims := map[string]interface{}{
+		"iss":   "https://issuer.example.com",
+		"sub":   "123",
+		"aud":   "client-id",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"email": "user@example.com",
+	}
+	tkn, err := verifier.Verify(context.Background(), signer.Sign(claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tkn.Subject != "123" {
+		t.Fatalf("expected subject: 123 but got %s", tkn.Subject)
+	}
+
+	var extra struct {
+		Email string `json:"email"`
+	}
+	if err := tkn.Claims(&extra); err != nil {
+		t.Fatal(err)
+	}
+	if extra.Email != "user@example.com" {
+		t.Fatalf("expected email: user@example.com but got %s", extra.Email)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	verifier := oidc.NewVerifier(signer.KeySet(), &oidc.Config{
+		ClientIDs: []string{"client-id"},
+		Issuers:   []string{"https://issuer.example.com"},
+	})
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": "https://issuer.example.com",
+			"sub": "123",
+			"aud": []string{"other", "client-id"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	if _, err := verifier.Verify(context.Background(), signer.Sign(valid())); err != nil {
+		t.Fatalf("expected array audience to be accepted but got %v", err)
+	}
+
+	expired := valid()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	if _, err := verifier.Verify(context.Background(), signer.Sign(expired)); !errors.Is(err, oidc.ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired but got %v", err)
+	}
+
+	wrongAud := valid()
+	wrongAud["aud"] = "other"
+	if _, err := verifier.Verify(context.Background(), signer.Sign(wrongAud)); err == nil {
+		t.Fatal("expected audience mismatch error")
+	}
+
+	wrongIss := valid()
+	wrongIss["iss"] = "https://evil.example.com"
+	if _, err := verifier.Verify(context.Background(), signer.Sign(wrongIss)); err == nil {
+		t.Fatal("expected issuer mismatch error")
+	}
+
+	other := oidctest.NewSigner("kid-1")
+	if _, err := verifier.Verify(context.Background(), other.Sign(valid())); !errors.Is(err, oidc.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature but got %v", err)
+	}
+}
+
+func TestVerifierEmptyClientID(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	verifier := oidc.NewVerifier(signer.KeySet(), &oidc.Config{
+		ClientIDs:       []string{"", "client-id"},
+		SkipIssuerCheck: true,
+	})
+
+	claims := map[string]interface{}{
+		"sub": "123",
+		"aud": "",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	if _, err := verifier.Verify(context.Background(), signer.Sign(claims)); err == nil {
+		t.Fatal("expected empty audience to be rejected")
+	}
+
+	verifier = oidc.NewVerifier(signer.KeySet(), &oidc.Config{ClientIDs: []string{""}, SkipIssuerCheck: true})
+	if _, err := verifier.Verify(context.Background(), signer.Sign(claims)); err != oidc.ErrNoClientID {
+		t.Fatalf("expected oidc.ErrNoClientID but got %v", err)
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(signer.JWKS())
+	}))
+	defer srv.Close()
+
+	keySet := oidc.NewRemoteKeySet(srv.URL, srv.Client())
+	for i := 0; i < 2; i++ {
+		if _, err := keySet.Key(context.Background(), "kid-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("expected key set to be fetched once but got %d", hits)
+	}
+
+	if _, err := keySet.Key(context.Background(), "unknown"); !errors.Is(err, oidc.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound but got %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected unknown key id to be rate limited but got %d fetches", hits)
+	}
+}
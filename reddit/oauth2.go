@@ -0,0 +1,82 @@
+package reddit
+
+import (
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler reddit.NewTokenHandler()
+// wrapped around the default reddit.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+		opts...,
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+//
+// The grant is temporary (1 hour, no refresh token) unless reddit.WithPermanentDuration() is
+// used.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...Option) http.Handler {
+	duration := "temporary"
+	if newOptions(opts).permanent {
+		duration = "permanent"
+	}
+
+	return autho2.NewLoginHandler(cfg, ckCfg, oauth2.SetAuthURLParam("duration", duration))
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+//
+// The token exchange is sent with the User-Agent set by reddit.WithUserAgent().
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	tokenHandler := autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tokenHandler.ServeHTTP(w, r.WithContext(contextWithUserAgent(r.Context(), o)))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewUserHandler creates a new reddit UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The user resource is set under the
+// request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*reddit.User)
+//
+// The UserModel used by default by the reddit.NewUserHandler is: https://www.reddit.com/dev/api/#GET_api_v1_me
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	userHandler := autho2.NewUserHandlerFunc(cfg, me, errHandler, terminalHandler)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		userHandler.ServeHTTP(w, r.WithContext(contextWithUserAgent(r.Context(), o)))
+	}
+
+	return http.HandlerFunc(f)
+}
@@ -0,0 +1,107 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	"golang.org/x/oauth2"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	const userAgent = "web:com.example.app:v1.0 (by /u/jane)"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != userAgent {
+			t.Errorf("expected token exchange user agent but got %q", r.UserAgent())
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client-id" || secret != "client-secret" {
+			t.Errorf("expected basic auth client credentials")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"token_type":    "bearer",
+			"expires_in":    86400,
+		})
+	})
+	mux.HandleFunc("/api/v1/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != userAgent {
+			t.Errorf("expected api user agent but got %q", r.UserAgent())
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":          "abc12",
+			"name":        "jane",
+			"total_karma": 42,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Scopes:       []string{"identity"},
+		Endpoint:     Endpoint,
+	}
+	ckCfg := autho.NewDebugCookieConfig("state")
+	opts := []Option{WithUserAgent(userAgent), WithPermanentDuration()}
+
+	// login phase.
+	w := httptest.NewRecorder()
+	NewLoginHandler(cfg, ckCfg, opts...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if loc.Query().Get("duration") != "permanent" {
+		t.Fatalf("expected permanent duration but got %s", loc.RawQuery)
+	}
+	stateCk := w.Result().Cookies()[0]
+
+	// callback phase.
+	var gotUser *User
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+	})
+	r := httptest.NewRequest(http.MethodGet, "/callback?code=auth-code&state="+stateCk.Value, nil)
+	r.AddCookie(stateCk)
+	w = httptest.NewRecorder()
+	NewCallbackHandler(cfg, ckCfg, nil, terminal, opts...).ServeHTTP(w, apitest.Request(r, srv))
+
+	if gotUser == nil || gotUser.ID != "abc12" || gotUser.TotalKarma != 42 {
+		t.Fatalf("unexpected user: %+v (%d: %s)", gotUser, w.Code, w.Body.String())
+	}
+}
+
+func TestContextWithUserAgent(t *testing.T) {
+	jar, _ := cookiejar.New(nil)
+	base := &http.Client{
+		Timeout: 5 * time.Second,
+		Jar:     jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, base)
+
+	client, _ := contextWithUserAgent(ctx, newOptions(nil)).Value(oauth2.HTTPClient).(*http.Client)
+	if client == nil || client == base {
+		t.Fatal("expected a copy of the context client")
+	}
+	if client.Timeout != base.Timeout || client.Jar != base.Jar || client.CheckRedirect == nil {
+		t.Fatalf("expected the context client settings to be kept but got %+v", client)
+	}
+	if tr, ok := client.Transport.(*userAgentTransport); !ok || tr.base != http.DefaultTransport {
+		t.Fatalf("expected the default transport to be wrapped but got %T", client.Transport)
+	}
+	if base.Transport != nil {
+		t.Fatal("expected the context client to be left untouched")
+	}
+}
@@ -0,0 +1,44 @@
+package reddit
+
+// DefaultUserAgent is the User-Agent sent when none is configured, reddit recommends a unique
+// and descriptive one: <platform>:<app ID>:<version string> (by /u/<reddit username>).
+const DefaultUserAgent string = "go:github.com/Lambels/autho:v1 (by /u/autho)"
+
+// Option configures the reddit handlers.
+type Option func(*options)
+
+type options struct {
+	userAgent string
+	permanent bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		userAgent: DefaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithUserAgent sets the User-Agent sent with every request to reddit, including the token
+// exchange. Reddit heavily rate limits generic user agents.
+//
+// https://github.com/reddit-archive/reddit/wiki/API#rules
+//
+// Default: reddit.DefaultUserAgent
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithPermanentDuration requests a permanent grant (duration=permanent), the token exchange then
+// returns a refresh token. Pass the option to the login handler.
+func WithPermanentDuration() Option {
+	return func(o *options) {
+		o.permanent = true
+	}
+}
@@ -0,0 +1,123 @@
+// Package reddit provides handlers for reddit.
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	"golang.org/x/oauth2"
+)
+
+// apiURL is the base url of the reddit oauth api.
+const apiURL string = "https://oauth.reddit.com"
+
+// Endpoint is the reddit oauth2 endpoint, reddit only accepts the client credentials with http
+// basic auth.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://www.reddit.com/api/v1/authorize",
+	TokenURL:  "https://www.reddit.com/api/v1/access_token",
+	AuthStyle: oauth2.AuthStyleInHeader,
+}
+
+// User represents fields accessible on a reddit account, requires the identity scope.
+//
+// https://www.reddit.com/dev/api/#GET_api_v1_me
+type User struct {
+	// ID is the base36 id of the account, its fullname is "t2_" + ID.
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	IconImg          string  `json:"icon_img"`
+	CreatedUTC       float64 `json:"created_utc"`
+	TotalKarma       int     `json:"total_karma"`
+	LinkKarma        int     `json:"link_karma"`
+	CommentKarma     int     `json:"comment_karma"`
+	HasVerifiedEmail bool    `json:"has_verified_email"`
+	IsEmployee       bool    `json:"is_employee"`
+	IsMod            bool    `json:"is_mod"`
+	IsSuspended      bool    `json:"is_suspended"`
+	Over18           bool    `json:"over_18"`
+	Verified         bool    `json:"verified"`
+}
+
+// Identity implements autho.Identifier, reddit doesn't expose the email of the user.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID: u.ID,
+	}
+}
+
+// APIError represents an error returned by the reddit api.
+type APIError struct {
+	Status  int    `json:"-"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("autho: reddit api error (%d): %s", e.Status, e.Message)
+}
+
+// userAgentTransport sets the User-Agent of every request.
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(r)
+}
+
+// contextWithUserAgent returns ctx holding the http client used by the oauth2 package for the
+// token exchange and as the base of the authorized client, sending the User-Agent. The client of
+// ctx (if any) is copied, only its transport is wrapped.
+func contextWithUserAgent(ctx context.Context, o *options) context.Context {
+	var client http.Client
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		client = *c
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &userAgentTransport{
+		userAgent: o.userAgent,
+		base:      base,
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &client)
+}
+
+func me(ctx context.Context, client *http.Client) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/api/v1/me", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+	if user.ID == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return &user, nil
+}
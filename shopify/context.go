@@ -0,0 +1,21 @@
+package shopify
+
+import "context"
+
+type shopKey struct{}
+
+type scopesKey struct{}
+
+// ShopFromContext harvests the verified myshopify domain of the shop (e.g.
+// "example.myshopify.com") from the request context, it is set by the token handler.
+func ShopFromContext(ctx context.Context) (string, bool) {
+	shop, ok := ctx.Value(shopKey{}).(string)
+	return shop, ok
+}
+
+// ScopesFromContext harvests the access scopes granted to the access token from the request
+// context, they are set by the token handler.
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).([]string)
+	return scopes, ok
+}
@@ -0,0 +1,137 @@
+package shopify
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler shopify.NewTokenHandler()
+// wrapped around the default shopify.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the shop for the users grant.
+//
+// The shop is read from the shop query parameter and must be a myshopify domain, the endpoint
+// of cfg is replaced by the endpoint of the shop. Install requests from shopify (hmac query
+// parameter present) are verified with cfg.ClientSecret. Invalid requests are passed to the
+// errHandler.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler http.Handler, opts ...Option) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	o := newOptions(opts)
+
+	var authOpts []oauth2.AuthCodeOption
+	if o.online {
+		authOpts = append(authOpts, oauth2.SetAuthURLParam("grant_options[]", "per-user"))
+	}
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		shop := query.Get("shop")
+		if !ValidShop(shop) {
+			autho.PassError(ErrInvalidShop, errHandler, w, r)
+			return
+		}
+		if query.Has("hmac") {
+			if err := VerifyHMAC(query, cfg.ClientSecret); err != nil {
+				autho.PassError(err, errHandler, w, r)
+				return
+			}
+		}
+
+		autho2.NewLoginHandler(configFor(cfg, shop), ckCfg, authOpts...).ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider. Before the token exchange the hmac of the callback is
+// verified with cfg.ClientSecret and the shop is validated, then the state is compared and the
+// auth code is exchanged with the shop for the access token.
+//
+// The shop and the granted access scopes are set under the request context alongside the token,
+// see shopify.ShopFromContext() and shopify.ScopesFromContext(), calling on success the
+// UserHandler.
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	if errHandler == nil {
+		errHandler = autho.DefaultFailureHandle
+	}
+	scopesHandler := newScopesHandler(errHandler, userHandler)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if err := VerifyHMAC(query, cfg.ClientSecret); err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+		shop := query.Get("shop")
+		if !ValidShop(shop) {
+			autho.PassError(ErrInvalidShop, errHandler, w, r)
+			return
+		}
+
+		shopCtx := context.WithValue(r.Context(), shopKey{}, shop)
+		tokenHandler := autho2.NewTokenHandler(configFor(cfg, shop), ckCfg, errHandler, scopesHandler)
+		tokenHandler.ServeHTTP(w, r.WithContext(shopCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// newScopesHandler sets the scopes granted to the token set by the token handler under the
+// request context before calling the user handler.
+func newScopesHandler(errHandler, userHandler http.Handler) http.Handler {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tkn, err := autho2.TokenFromContext(r.Context())
+		if err != nil {
+			autho.PassError(err, errHandler, w, r)
+			return
+		}
+
+		scopesCtx := context.WithValue(r.Context(), scopesKey{}, autho2.GrantedScopes(tkn))
+		userHandler.ServeHTTP(w, r.WithContext(scopesCtx))
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// NewUserHandler creates a new shopify UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the shop resource from the admin api. The shop resource
+// is set under the request context.
+//
+//	shop, ok := autho.UserFromContext(r.Context()).(*shopify.Shop)
+//
+// The UserModel used by default by the shopify.NewUserHandler is: https://shopify.dev/docs/api/admin-rest/latest/resources/shop
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	fetch := func(ctx context.Context, client *http.Client) (*Shop, error) {
+		return me(ctx, client, o)
+	}
+
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
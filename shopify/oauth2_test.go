@@ -0,0 +1,204 @@
+package shopify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	"golang.org/x/oauth2"
+)
+
+// sign adds the hmac of query, computed with secret, to query. The message follows the form
+// documented by shopify: sorted key=value pairs joined by "&", "%" and "&" escaped in keys and
+// values, "=" escaped in keys and array parameters written as key=["a", "b"].
+func sign(query url.Values, secret string) url.Values {
+	escape := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "%", "%25"), "&", "%26")
+	}
+
+	var pairs []string
+	for k, vs := range query {
+		key := strings.ReplaceAll(escape(strings.TrimSuffix(k, "[]")), "=", "%3D")
+		if strings.HasSuffix(k, "[]") {
+			var quoted []string
+			for _, v := range vs {
+				quoted = append(quoted, `"`+escape(v)+`"`)
+			}
+			pairs = append(pairs, key+"=["+strings.Join(quoted, ", ")+"]")
+			continue
+		}
+		pairs = append(pairs, key+"="+escape(vs[0]))
+	}
+	sort.Strings(pairs)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(pairs, "&")))
+	query.Set("hmac", hex.EncodeToString(mac.Sum(nil)))
+	return query
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	tests := map[string]struct {
+		query  url.Values
+		secret string
+		valid  bool
+	}{
+		"valid": {
+			query:  sign(url.Values{"shop": {"example.myshopify.com"}, "timestamp": {"1700000000"}}, "api-secret"),
+			secret: "api-secret",
+			valid:  true,
+		},
+		"escaped": {
+			query:  sign(url.Values{"shop": {"example.myshopify.com"}, "note": {"a&b=c%d"}, "we&ird=key": {"1"}}, "api-secret"),
+			secret: "api-secret",
+			valid:  true,
+		},
+		"array": {
+			query:  sign(url.Values{"shop": {"example.myshopify.com"}, "ids[]": {"2", "1"}}, "api-secret"),
+			secret: "api-secret",
+			valid:  true,
+		},
+		"unescaped": {
+			query: func() url.Values {
+				mac := hmac.New(sha256.New, []byte("api-secret"))
+				mac.Write([]byte("note=a&b=c%d&shop=example.myshopify.com"))
+				return url.Values{"shop": {"example.myshopify.com"}, "note": {"a&b=c%d"}, "hmac": {hex.EncodeToString(mac.Sum(nil))}}
+			}(),
+			secret: "api-secret",
+		},
+		"forged": {
+			query:  sign(url.Values{"shop": {"example.myshopify.com"}}, "other-secret"),
+			secret: "api-secret",
+		},
+		"empty secret": {
+			query: sign(url.Values{"shop": {"example.myshopify.com"}}, ""),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := VerifyHMAC(tc.query, tc.secret); (err == nil) != tc.valid {
+				t.Fatalf("expected valid: %t but got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidShop(t *testing.T) {
+	for shop, valid := range map[string]bool{
+		"example.myshopify.com":          true,
+		"my-shop-1.myshopify.com":        true,
+		"-example.myshopify.com":         false,
+		"example.myshopify.com.evil.com": false,
+		"evil.com/example.myshopify.com": false,
+		"example.shopify.com":            false,
+	} {
+		if ValidShop(shop) != valid {
+			t.Errorf("expected ValidShop(%q) to be %v", shop, valid)
+		}
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	var exchanges int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "shpat_token",
+			"scope":        "read_orders,write_products",
+		})
+	})
+	mux.HandleFunc("/admin/api/"+DefaultAPIVersion+"/shop.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Shopify-Access-Token") != "shpat_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"shop": map[string]interface{}{
+				"id":               1,
+				"name":             "Example",
+				"myshopify_domain": "example.myshopify.com",
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{ClientID: "api-key", ClientSecret: "api-secret", Scopes: []string{"read_orders", "write_products"}}
+	ckCfg := autho.NewDebugCookieConfig("state")
+
+	// login phase.
+	w := httptest.NewRecorder()
+	NewLoginHandler(cfg, ckCfg, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login?shop=example.myshopify.com", nil))
+	if loc := w.Header().Get("Location"); !strings.HasPrefix(loc, "https://example.myshopify.com/admin/oauth/authorize?") {
+		t.Fatalf("expected redirect to the shop but got %q", loc)
+	}
+	stateCk := w.Result().Cookies()[0]
+
+	tests := map[string]struct {
+		query    url.Values
+		accepted bool
+	}{
+		"valid": {
+			query:    sign(url.Values{"code": {"auth-code"}, "shop": {"example.myshopify.com"}, "state": {stateCk.Value}, "timestamp": {"1700000000"}}, "api-secret"),
+			accepted: true,
+		},
+		"forged hmac": {
+			query: sign(url.Values{"code": {"auth-code"}, "shop": {"example.myshopify.com"}, "state": {stateCk.Value}}, "other-secret"),
+		},
+		"tampered shop": {
+			query: func() url.Values {
+				q := sign(url.Values{"code": {"auth-code"}, "shop": {"example.myshopify.com"}, "state": {stateCk.Value}}, "api-secret")
+				q.Set("shop", "evil.myshopify.com")
+				return q
+			}(),
+		},
+		"invalid shop": {
+			query: sign(url.Values{"code": {"auth-code"}, "shop": {"evil.com"}, "state": {stateCk.Value}}, "api-secret"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			exchanges = 0
+
+			var gotShop *Shop
+			var gotDomain string
+			var gotScopes []string
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotShop, _ = autho.UserFromContext(r.Context()).(*Shop)
+				gotDomain, _ = ShopFromContext(r.Context())
+				gotScopes, _ = ScopesFromContext(r.Context())
+			})
+			r := httptest.NewRequest(http.MethodGet, "/callback?"+tc.query.Encode(), nil)
+			r.AddCookie(stateCk)
+			w := httptest.NewRecorder()
+			NewCallbackHandler(cfg, ckCfg, nil, terminal).ServeHTTP(w, apitest.Request(r, srv))
+
+			if !tc.accepted {
+				if exchanges != 0 || w.Code != http.StatusBadRequest {
+					t.Fatalf("expected callback to be rejected before the exchange but got %d (%d exchanges)", w.Code, exchanges)
+				}
+				return
+			}
+
+			if gotShop == nil || gotShop.Name != "Example" || gotDomain != "example.myshopify.com" {
+				t.Fatalf("unexpected shop: %+v %q (%d: %s)", gotShop, gotDomain, w.Code, w.Body.String())
+			}
+			if len(gotScopes) != 2 || gotScopes[0] != "read_orders" || gotScopes[1] != "write_products" {
+				t.Fatalf("unexpected scopes: %v", gotScopes)
+			}
+		})
+	}
+}
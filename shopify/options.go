@@ -0,0 +1,42 @@
+package shopify
+
+// DefaultAPIVersion is the admin api version used by default by the shopify.NewUserHandler.
+const DefaultAPIVersion string = "2024-10"
+
+// Option configures the shopify handlers.
+type Option func(*options)
+
+type options struct {
+	apiVersion string
+	online     bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		apiVersion: DefaultAPIVersion,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithAPIVersion sets the admin api version (e.g. "2024-10") used by the shopify.NewUserHandler.
+//
+// Default: shopify.DefaultAPIVersion
+func WithAPIVersion(version string) Option {
+	return func(o *options) {
+		o.apiVersion = version
+	}
+}
+
+// WithOnlineAccess requests an online access token (grant_options[]=per-user), bound to the
+// staff member signing in instead of the shop. Pass the option to the login handler.
+//
+// https://shopify.dev/docs/apps/build/authentication-authorization/access-token-types/online-access-tokens
+func WithOnlineAccess() Option {
+	return func(o *options) {
+		o.online = true
+	}
+}
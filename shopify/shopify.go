@@ -0,0 +1,201 @@
+// Package shopify provides handlers for shopify apps, the oauth2 endpoints of shopify are
+// specific to each shop.
+package shopify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+var shopPattern *regexp.Regexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-]*\.myshopify\.com$`)
+
+// ErrInvalidShop represents a shop parameter which isn't a myshopify domain.
+var ErrInvalidShop error = errors.New("autho: shop is not a valid myshopify domain")
+
+// ErrInvalidHMAC represents a request whose hmac parameter doesn't match the app secret.
+var ErrInvalidHMAC error = errors.New("autho: request hmac is invalid")
+
+// ValidShop reports whether shop is a myshopify domain, e.g. "example.myshopify.com".
+func ValidShop(shop string) bool {
+	return shopPattern.MatchString(shop)
+}
+
+// ShopEndpoint returns the oauth2 endpoint of shop.
+func ShopEndpoint(shop string) oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:   "https://" + shop + "/admin/oauth/authorize",
+		TokenURL:  "https://" + shop + "/admin/oauth/access_token",
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+}
+
+// configFor returns cfg targeting the oauth2 endpoint of shop.
+func configFor(cfg *oauth2.Config, shop string) *oauth2.Config {
+	shopCfg := *cfg
+	shopCfg.Endpoint = ShopEndpoint(shop)
+	return &shopCfg
+}
+
+// VerifyHMAC verifies the hmac parameter of a request sent by shopify (install request, oauth
+// callback), which is the hex encoded HMAC-SHA256 of the other query parameters with the app
+// secret. An empty app secret is rejected as anyone could sign the request with it.
+//
+// The signed message is made of the parameters sorted by key and joined as key=value pairs by
+// "&", with "%" and "&" escaped in keys and values and "=" escaped in keys. Array parameters
+// (ids[]=1&ids[]=2) are signed as ids=["1", "2"].
+//
+// https://shopify.dev/docs/apps/build/authentication-authorization/access-tokens/authorization-code-grant#step-1-verify-the-installation-request
+func VerifyHMAC(query url.Values, secret string) error {
+	if secret == "" {
+		return errors.New("autho: app secret required to verify the hmac")
+	}
+
+	sig, err := hex.DecodeString(query.Get("hmac"))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalidHMAC
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(hmacMessage(query)))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrInvalidHMAC
+	}
+
+	return nil
+}
+
+var (
+	hmacKeyEscaper   = strings.NewReplacer("%", "%25", "&", "%26", "=", "%3D")
+	hmacValueEscaper = strings.NewReplacer("%", "%25", "&", "%26")
+)
+
+// hmacMessage builds the message signed by shopify from the query parameters.
+func hmacMessage(query url.Values) string {
+	pairs := make(map[string]string, len(query))
+	keys := make([]string, 0, len(query))
+	for k, vs := range query {
+		if k == "hmac" || k == "signature" {
+			continue
+		}
+
+		key := hmacKeyEscaper.Replace(strings.TrimSuffix(k, "[]"))
+		var value string
+		if strings.HasSuffix(k, "[]") {
+			quoted := make([]string, 0, len(vs))
+			for _, v := range vs {
+				quoted = append(quoted, `"`+hmacValueEscaper.Replace(v)+`"`)
+			}
+			value = "[" + strings.Join(quoted, ", ") + "]"
+		} else {
+			value = hmacValueEscaper.Replace(strings.Join(vs, ","))
+		}
+
+		pairs[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	msg := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msg = append(msg, k+"="+pairs[k])
+	}
+
+	return strings.Join(msg, "&")
+}
+
+// Shop represents the shop the app was installed on.
+//
+// https://shopify.dev/docs/api/admin-rest/latest/resources/shop
+type Shop struct {
+	ID              int64  `json:"id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Domain          string `json:"domain"`
+	MyshopifyDomain string `json:"myshopify_domain"`
+	ShopOwner       string `json:"shop_owner"`
+	PlanName        string `json:"plan_name"`
+	Currency        string `json:"currency"`
+	Country         string `json:"country_code"`
+	IANATimezone    string `json:"iana_timezone"`
+}
+
+// Identity implements autho.Identifier, the shop email isn't verified.
+func (s *Shop) Identity() autho.Identity {
+	return autho.Identity{
+		ID:    s.MyshopifyDomain,
+		Email: s.Email,
+	}
+}
+
+// APIError represents an error returned by the shopify admin api.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("autho: shopify api error (%d): %s", e.Status, e.Message)
+}
+
+func me(ctx context.Context, client *http.Client, o *options) (*Shop, error) {
+	shop, ok := ShopFromContext(ctx)
+	if !ok {
+		return nil, errors.New("autho: shop parameter not set")
+	}
+
+	tkn, err := autho2.TokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := "https://" + shop + "/admin/api/" + o.apiVersion + "/shop.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the admin api authenticates with its own header instead of the bearer token.
+	req.Header.Set("X-Shopify-Access-Token", tkn.AccessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors interface{} `json:"errors"`
+		}
+		apiErr := &APIError{Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Errors != nil {
+			apiErr.Message = fmt.Sprint(body.Errors)
+		}
+		return nil, apiErr
+	}
+
+	var body struct {
+		Shop *Shop `json:"shop"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Shop == nil || body.Shop.MyshopifyDomain == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return body.Shop, nil
+}
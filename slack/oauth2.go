@@ -0,0 +1,70 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// NewCallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler slack.NewTokenHandler()
+// wrapped around the default slack.NewUserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewCallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewTokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewUserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+	)
+}
+
+// NewLoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+//
+// cfg.Scopes must include "openid", add "email" and "profile" for the email and profile claims.
+func NewLoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, opts ...Option) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg, newOptions(opts).authCodeOptions()...)
+}
+
+// NewTokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+func NewTokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+}
+
+// NewUserHandler creates a new slack UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The user is read from the verified id
+// token of the token response, its workspace must be allowed by slack.WithTeams() or
+// slack.WithEnterprises(). The user resource is set under the request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*slack.User)
+func NewUserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	verifier := newVerifier(cfg, o)
+
+	fetch := func(ctx context.Context, _ *http.Client) (*User, error) {
+		tkn, err := autho2.TokenFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return userFromTokenIDToken(ctx, verifier, o, tkn)
+	}
+
+	return autho2.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
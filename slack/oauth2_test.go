@@ -0,0 +1,95 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/oidctest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+func serveUserHandler(h http.Handler, idToken string) {
+	tkn := (&oauth2.Token{AccessToken: "access-token"}).WithExtra(map[string]interface{}{
+		"id_token": idToken,
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(autho2.ContextWithToken(r.Context(), tkn))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestUserHandlerWorkspaces(t *testing.T) {
+	signer := oidctest.NewSigner("kid-1")
+	cfg := &oauth2.Config{ClientID: "client-id"}
+	idClaims := func(teamID, enterpriseID string) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":                       Issuer,
+			"sub":                       "U0R7JM",
+			"aud":                       "client-id",
+			"exp":                       time.Now().Add(time.Hour).Unix(),
+			"https://slack.com/user_id": "U0R7JM",
+			"https://slack.com/team_id": teamID,
+			"email":                     "jane@example.com",
+			"email_verified":            true,
+		}
+		if enterpriseID != "" {
+			c["https://slack.com/enterprise_id"] = enterpriseID
+		}
+		return c
+	}
+
+	tests := map[string]struct {
+		claims   map[string]interface{}
+		accepted bool
+	}{
+		"allowed team":       {claims: idClaims("T0R7GR", ""), accepted: true},
+		"allowed enterprise": {claims: idClaims("T9999", "E12345"), accepted: true},
+		"other team":         {claims: idClaims("T9999", "")},
+		"other enterprise":   {claims: idClaims("T9999", "E99999")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotUser *User
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+			})
+			var gotErr error
+			errHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotErr = autho.ErrorFromContext(r.Context())
+			})
+			h := NewUserHandler(cfg, errHandler, terminal, WithKeySet(signer.KeySet()), WithTeams("T0R7GR"), WithEnterprises("E12345"))
+
+			serveUserHandler(h, signer.Sign(tc.claims))
+
+			if !tc.accepted {
+				var notAuthorized *autho.ErrUserNotAuthorized
+				if gotUser != nil || !errors.As(gotErr, &notAuthorized) {
+					t.Fatalf("expected *autho.ErrUserNotAuthorized but got %v", gotErr)
+				}
+				return
+			}
+			if gotUser == nil || gotUser.ID != "U0R7JM" || gotUser.TeamID != tc.claims["https://slack.com/team_id"] {
+				t.Fatalf("unexpected user: %+v (%v)", gotUser, gotErr)
+			}
+		})
+	}
+}
+
+func TestLoginHandlerTeamHint(t *testing.T) {
+	cfg := &oauth2.Config{ClientID: "client-id", Endpoint: Endpoint, Scopes: []string{"openid"}}
+	h := NewLoginHandler(cfg, autho.NewDebugCookieConfig("state"), WithTeams("T0R7GR"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if loc.Query().Get("team") != "T0R7GR" {
+		t.Fatalf("expected team hint but got %s", loc.RawQuery)
+	}
+}
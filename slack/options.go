@@ -0,0 +1,87 @@
+package slack
+
+import (
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Option configures the slack handlers.
+type Option func(*options)
+
+type options struct {
+	keySet      oidc.KeySet
+	teams       []string
+	enterprises []string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		keySet: defaultKeySet,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithKeySet sets the key set used to verify the signature of slack id tokens.
+//
+// Defaults to slacks JSON Web Key Set which is fetched and cached by max-age.
+func WithKeySet(keySet oidc.KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+	}
+}
+
+// WithTeams restricts sign in to users of one of the workspaces (team ids). When combined with
+// slack.WithEnterprises() users of either are accepted, other users are passed to the error
+// handler as an *autho.ErrUserNotAuthorized.
+//
+// Pass the option to both the login and callback handlers, the login handler then sends the
+// team hint to slack when a single team is allowed.
+func WithTeams(teamIDs ...string) Option {
+	return func(o *options) {
+		o.teams = append(o.teams, teamIDs...)
+	}
+}
+
+// WithEnterprises restricts sign in to users of the workspaces of one of the Enterprise Grid
+// organizations (enterprise ids).
+func WithEnterprises(enterpriseIDs ...string) Option {
+	return func(o *options) {
+		o.enterprises = append(o.enterprises, enterpriseIDs...)
+	}
+}
+
+func (o *options) authCodeOptions() []oauth2.AuthCodeOption {
+	if len(o.teams) == 1 && len(o.enterprises) == 0 {
+		return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("team", o.teams[0])}
+	}
+
+	return nil
+}
+
+// authorize checks the id token claims against the allowed teams and enterprises.
+func (o *options) authorize(c *claims) error {
+	if len(o.teams) == 0 && len(o.enterprises) == 0 {
+		return nil
+	}
+
+	if contains(o.teams, c.TeamID) || (c.EnterpriseID != "" && contains(o.enterprises, c.EnterpriseID)) {
+		return nil
+	}
+
+	return &autho.ErrUserNotAuthorized{Reason: "slack workspace is not allowed"}
+}
+
+func contains(set []string, s string) bool {
+	for _, v := range set {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
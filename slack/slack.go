@@ -0,0 +1,123 @@
+// Package slack provides handlers for "Sign in with Slack", slacks OpenID Connect flow.
+package slack
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Issuer is the issuer of slack id tokens.
+const Issuer string = "https://slack.com"
+
+// KeysURL is the JSON Web Key Set used by slack to sign id tokens.
+const KeysURL string = "https://slack.com/openid/connect/keys"
+
+var defaultKeySet oidc.KeySet = oidc.NewRemoteKeySet(KeysURL, nil)
+
+// Endpoint is the slack OpenID Connect endpoint.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://slack.com/openid/connect/authorize",
+	TokenURL: "https://slack.com/api/openid.connect.token",
+}
+
+// User represents the identity of a slack user found in the id token.
+//
+// https://api.slack.com/authentication/sign-in-with-slack#response
+type User struct {
+	// ID is the slack user id (https://slack.com/user_id claim).
+	ID string
+	// TeamID is the id of the workspace of the user (https://slack.com/team_id claim).
+	TeamID string
+	// EnterpriseID is the id of the Enterprise Grid organization of the workspace, empty outside
+	// of Enterprise Grid (https://slack.com/enterprise_id claim).
+	EnterpriseID  string
+	TeamName      string
+	TeamDomain    string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+	Picture       string
+	Locale        string
+}
+
+// Identity implements autho.Identifier. Slack user ids are unique per workspace, the id is
+// scoped by the team id.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID:            u.TeamID + "/" + u.ID,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+	}
+}
+
+type claims struct {
+	Subject       string `json:"sub"`
+	UserID        string `json:"https://slack.com/user_id"`
+	TeamID        string `json:"https://slack.com/team_id"`
+	EnterpriseID  string `json:"https://slack.com/enterprise_id"`
+	TeamName      string `json:"https://slack.com/team_name"`
+	TeamDomain    string `json:"https://slack.com/team_domain"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+	Locale        string `json:"locale"`
+}
+
+func newVerifier(cfg *oauth2.Config, o *options) *oidc.Verifier {
+	return oidc.NewVerifier(o.keySet, &oidc.Config{
+		ClientIDs: []string{cfg.ClientID},
+		Issuers:   []string{Issuer},
+	})
+}
+
+// userFromTokenIDToken verifies the id token returned alongside the access token in the token
+// response and checks its workspace against the allowed teams and enterprises.
+func userFromTokenIDToken(ctx context.Context, verifier *oidc.Verifier, o *options, tkn *oauth2.Token) (*User, error) {
+	raw, ok := tkn.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("autho: id token missing from token response, request the openid scope.")
+	}
+
+	idTkn, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := idTkn.Claims(&c); err != nil {
+		return nil, err
+	}
+	if c.UserID == "" {
+		c.UserID = c.Subject
+	}
+	if c.UserID == "" || c.TeamID == "" {
+		return nil, autho.ErrNoUser
+	}
+	if err := o.authorize(&c); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:            c.UserID,
+		TeamID:        c.TeamID,
+		EnterpriseID:  c.EnterpriseID,
+		TeamName:      c.TeamName,
+		TeamDomain:    c.TeamDomain,
+		Email:         c.Email,
+		EmailVerified: c.EmailVerified,
+		Name:          c.Name,
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+		Picture:       c.Picture,
+		Locale:        c.Locale,
+	}, nil
+}
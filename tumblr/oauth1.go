@@ -35,17 +35,17 @@ func NewCallbackHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandle
 //
 // tumblr requires the request secret to be persisted throughout the callbacks.
 func NewLoginHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler http.Handler) http.Handler {
-	return autho1.NewLoginHandler(cfg, ckCfg, errHandler)
+	return autho1.NewLoginHandler(cfg, ckCfg, errHandler, autho1.WithRequestSecret())
 }
 
 // NewTokenHandler creates a new TokenHandler which is responsible for exchanging the
-// request token, request secret (if ckCfg != nil) and verifier for the access token and
+// request token, request secret and verifier for the access token and
 // access secret. Following the exchange the token handler is responsible to add the access token
 // under the request ctx, calling on success the userHandler.
 //
 // tumblr requires to read the request secret in the login handler.
 func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
-	return autho1.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+	return autho1.NewTokenHandler(cfg, ckCfg, errHandler, userHandler, autho1.WithRequestSecret())
 }
 
 // NewUserHandler creates a new tumblr UserHandler resposnible for using the tokens provided
@@ -56,29 +56,5 @@ func NewTokenHandler(cfg *oauth1.Config, ckCfg *autho.CookieConfig, errHandler,
 //
 // The UserModel used by default by the tumblr.NewUserHandler is: https://www.tumblr.com/docs/en/api/v2#userinfo--get-a-users-information
 func NewUserHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
-	}
-
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho1.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
-
-		user, err := me(cfg.Client(
-			r.Context(),
-			tkn,
-		))
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
-
-		userCtx := autho.ContextWithUser(r.Context(), user)
-		terminalHandler.ServeHTTP(w, r.WithContext(userCtx))
-	}
-
-	return http.HandlerFunc(f)
+	return autho1.NewUserHandlerFunc(cfg, me, errHandler, terminalHandler)
 }
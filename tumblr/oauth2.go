@@ -0,0 +1,63 @@
+package tumblr
+
+import (
+	"net/http"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is the tumblr oauth2 endpoint.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://www.tumblr.com/oauth2/authorize",
+	TokenURL: "https://api.tumblr.com/v2/oauth2/token",
+}
+
+// NewOAuth2CallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler tumblr.NewOAuth2TokenHandler()
+// wrapped around the default tumblr.NewOAuth2UserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewOAuth2CallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler) http.Handler {
+	return NewOAuth2TokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewOAuth2UserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+		),
+	)
+}
+
+// NewOAuth2LoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie. Afterwards the login handler is also
+// responsible for redirecting the user to the provider for the users grant.
+//
+// Add the "offline_access" scope to cfg.Scopes to obtain a refresh token.
+func NewOAuth2LoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	return autho2.NewLoginHandler(cfg, ckCfg)
+}
+
+// NewOAuth2TokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange and adds the token to the request context, calling on
+// success the UserHandler.
+func NewOAuth2TokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewTokenHandler(cfg, ckCfg, errHandler, userHandler)
+}
+
+// NewOAuth2UserHandler creates a new tumblr UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource. The user resource is set under the
+// request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*tumblr.User)
+//
+// The UserModel used by default by the tumblr.NewOAuth2UserHandler is: https://www.tumblr.com/docs/en/api/v2#userinfo--get-a-users-information
+func NewOAuth2UserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler) http.Handler {
+	return autho2.NewUserHandlerFunc(cfg, me, errHandler, terminalHandler)
+}
@@ -1,7 +1,9 @@
 package tumblr
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/Lambels/autho"
@@ -10,10 +12,20 @@ import (
 const profileEndpoint string = "https://api.tumblr.com/v2/user/info"
 
 type meta struct {
-	Status int    `json:"stauts"`
+	Status int    `json:"status"`
 	Msg    string `json:"msg"`
 }
 
+// APIError represents an error status returned by the tumblr api.
+type APIError struct {
+	Status int
+	Msg    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("autho: tumblr api error (%d): %s", e.Status, e.Msg)
+}
+
 // User represents fields accessible on a tumblr account.
 //
 // https://www.tumblr.com/docs/en/api/v2#userinfo--get-a-users-information
@@ -22,15 +34,34 @@ type User struct {
 	// The ammout of people the user follows.
 	Following int `json:"following"`
 	Likes     int `json:"likes"`
+	// Blogs are the blogs owned by the user.
+	Blogs []Blog `json:"blogs"`
+}
+
+// Blog represents a blog owned by a tumblr user.
+type Blog struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// Primary reports if the blog is the primary blog of the user.
+	Primary bool `json:"primary"`
+}
+
+// Identity implements autho.Identifier, tumblr doesn't expose the email of the user.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID: u.Name,
+	}
 }
 
 type response struct {
-	Metadata meta  `json:"meta"`
-	UserInfo *User `json:"response"`
+	Metadata meta `json:"meta"`
+	// Response holds the user info on success and an empty array on errors.
+	Response json.RawMessage `json:"response"`
 }
 
-func me(client *http.Client) (*User, error) {
-	req, err := http.NewRequest(http.MethodGet, profileEndpoint, nil)
+func me(ctx context.Context, client *http.Client) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, profileEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,19 +70,34 @@ func me(client *http.Client) (*User, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
 	return validateResp(resp)
 }
 
 func validateResp(resp *http.Response) (*User, error) {
-	if resp.StatusCode != http.StatusOK {
+	var data response
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{Status: resp.StatusCode, Msg: http.StatusText(resp.StatusCode)}
+		}
 		return nil, autho.ErrNoUser
 	}
 
-	var data response
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if resp.StatusCode != http.StatusOK || (data.Metadata.Status != 0 && data.Metadata.Status != http.StatusOK) {
+		status := data.Metadata.Status
+		if status == 0 {
+			status = resp.StatusCode
+		}
+		return nil, &APIError{Status: status, Msg: data.Metadata.Msg}
+	}
+
+	var info struct {
+		User *User `json:"user"`
+	}
+	if err := json.Unmarshal(data.Response, &info); err != nil || info.User == nil {
 		return nil, autho.ErrNoUser
 	}
 
-	return data.UserInfo, nil
+	return info.User, nil
 }
@@ -0,0 +1,50 @@
+package tumblr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newResponse(status int, body string) *http.Response {
+	w := httptest.NewRecorder()
+	w.WriteHeader(status)
+	w.WriteString(body)
+	return w.Result()
+}
+
+func TestValidateResp(t *testing.T) {
+	user, err := validateResp(newResponse(http.StatusOK, `{
+		"meta": {"status": 200, "msg": "OK"},
+		"response": {"user": {
+			"name": "jane",
+			"following": 3,
+			"blogs": [
+				{"name": "jane", "url": "https://jane.tumblr.com/", "primary": true},
+				{"name": "jane-art", "url": "https://jane-art.tumblr.com/", "primary": false}
+			]
+		}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "jane" || len(user.Blogs) != 2 || !user.Blogs[0].Primary || user.Blogs[1].URL != "https://jane-art.tumblr.com/" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func TestValidateRespError(t *testing.T) {
+	_, err := validateResp(newResponse(http.StatusUnauthorized, `{
+		"meta": {"status": 401, "msg": "Unauthorized"},
+		"response": []
+	}`))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError but got %v", err)
+	}
+	if apiErr.Status != http.StatusUnauthorized || apiErr.Msg != "Unauthorized" {
+		t.Fatalf("unexpected error: %+v", apiErr)
+	}
+}
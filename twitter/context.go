@@ -0,0 +1,23 @@
+package twitter
+
+import (
+	"context"
+
+	autho1 "github.com/Lambels/autho/oauth1"
+	"github.com/dghubble/oauth1"
+)
+
+// AccessTokenFromContext harvests the oauth1 access token and access secret of the user from
+// the request context, they are set by the token handler and remain accessible in the terminal
+// handler. Persist them to make requests on the users behalf later.
+//
+//	tkn, ok := twitter.AccessTokenFromContext(r.Context())
+//	client := cfg.Client(ctx, oauth1.NewToken(tkn.Token, tkn.TokenSecret))
+func AccessTokenFromContext(ctx context.Context) (*oauth1.Token, bool) {
+	tkn, err := autho1.TokenFromContext(ctx)
+	if err != nil || tkn.Token == "" {
+		return nil, false
+	}
+
+	return tkn, true
+}
@@ -1,6 +1,7 @@
 package twitter
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Lambels/autho"
@@ -15,7 +16,7 @@ import (
 //
 // This method saves allot of boilerplate. For more customisable handlers construct your
 // own callback handler by wrapping your own specific token handler around your own specific user handler.
-func NewCallbackHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Handler) http.Handler {
+func NewCallbackHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
 	return NewTokenHandler(
 		cfg,
 		errHandler,
@@ -23,6 +24,7 @@ func NewCallbackHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Han
 			cfg,
 			errHandler,
 			terminalHandler,
+			opts...,
 		),
 	)
 }
@@ -44,41 +46,47 @@ func NewTokenHandler(cfg *oauth1.Config, errHandler, userHandler http.Handler) h
 // by the TokenHandler in exchange for the users resource. The user resource is set under the
 // request context.
 //
+// The email of the user is only requested when using twitter.WithEmail(), the access token and
+// secret of the user are accessible with twitter.AccessTokenFromContext().
+//
 //	user, ok := autho.UserFromContext(r.Context()).(*twitter.User)
 //
 // The UserModel used by default by the twitter.NewUserHandler is: https://pkg.go.dev/github.com/dghubble/go-twitter/twitter#User
-func NewUserHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Handler) http.Handler {
-	if errHandler == nil {
-		errHandler = autho.DefaultFailureHandle
+func NewUserHandler(cfg *oauth1.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	fetch := func(ctx context.Context, client *http.Client) (*twitter.User, error) {
+		return me(ctx, client, o)
 	}
 
-	f := func(w http.ResponseWriter, r *http.Request) {
-		tkn, err := autho1.TokenFromContext(r.Context())
-		if err != nil {
-			autho.PassError(err, errHandler, w, r)
-			return
-		}
+	terminalHandler = autho.NewIdentityHandler(identity, terminalHandler)
+	return autho1.NewUserHandlerFunc(cfg, fetch, errHandler, terminalHandler)
+}
 
-		httpClient := cfg.Client(r.Context(), tkn)
-		client := twitter.NewClient(httpClient)
+func me(_ context.Context, client *http.Client, o *options) (*twitter.User, error) {
+	user, resp, err := twitter.NewClient(client).Accounts.VerifyCredentials(&twitter.AccountVerifyParams{
+		IncludeEntities: twitter.Bool(false),
+		SkipStatus:      twitter.Bool(true),
+		IncludeEmail:    twitter.Bool(o.email),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, autho.ErrNoUser
+	}
+	if user == nil || user.ID == 0 {
+		return nil, autho.ErrNoUser
+	}
 
-		user, resp, err := client.Accounts.VerifyCredentials(&twitter.AccountVerifyParams{
-			IncludeEntities: twitter.Bool(false),
-			SkipStatus:      twitter.Bool(true),
-			IncludeEmail:    twitter.Bool(false),
-		})
-		if err != nil || resp.StatusCode != http.StatusOK {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
-		}
-		if user == nil || user.ID == 0 {
-			autho.PassError(autho.ErrNoUser, errHandler, w, r)
-			return
-		}
+	return user, nil
+}
 
-		r = r.WithContext(autho.ContextWithUser(r.Context(), user))
-		terminalHandler.ServeHTTP(w, r)
+// identity describes the identity of the twitter user, twitter only returns verified emails.
+func identity(u *twitter.User) autho.Identity {
+	return autho.Identity{
+		ID:            u.IDStr,
+		Email:         u.Email,
+		EmailVerified: u.Email != "",
 	}
-
-	return http.HandlerFunc(f)
 }
@@ -0,0 +1,87 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	autho1 "github.com/Lambels/autho/oauth1"
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+)
+
+// serveOAuth1UserHandler serves h with an access token in the request context, requests to the
+// twitter api are sent to srv.
+func serveOAuth1UserHandler(h http.Handler, srv *httptest.Server) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := autho1.ContextWithToken(apitest.Context(r.Context(), srv), oauth1.NewToken("access-token", "access-secret"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r.WithContext(ctx))
+	return w
+}
+
+func TestUserHandlerEmail(t *testing.T) {
+	tests := map[string]struct {
+		opts  []Option
+		email string
+	}{
+		"with email":    {opts: []Option{WithEmail()}, email: "jane@example.com"},
+		"without email": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/1.1/account/verify_credentials.json" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				user := map[string]interface{}{"id": 1, "id_str": "1", "screen_name": "jane"}
+				if r.URL.Query().Get("include_email") == "true" {
+					user["email"] = "jane@example.com"
+				}
+				json.NewEncoder(w).Encode(user)
+			}))
+			defer srv.Close()
+
+			var gotUser *twitter.User
+			terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = autho.UserFromContextAs[*twitter.User](r.Context())
+			})
+			w := serveOAuth1UserHandler(NewUserHandler(&oauth1.Config{}, nil, terminal, tc.opts...), srv)
+
+			if gotUser == nil {
+				t.Fatalf("expected user in context but got %d: %s", w.Code, w.Body.String())
+			}
+			if gotUser.Email != tc.email {
+				t.Fatalf("expected email %q but got %q", tc.email, gotUser.Email)
+			}
+		})
+	}
+}
+
+func TestAccessTokenFromContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "id_str": "1"})
+	}))
+	defer srv.Close()
+
+	var (
+		got *oauth1.Token
+		ok  bool
+	)
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = AccessTokenFromContext(r.Context())
+	})
+	serveOAuth1UserHandler(NewUserHandler(&oauth1.Config{}, nil, terminal), srv)
+
+	if !ok || got.Token != "access-token" || got.TokenSecret != "access-secret" {
+		t.Fatalf("expected access token in terminal handler but got %v", got)
+	}
+	if _, ok := AccessTokenFromContext(context.Background()); ok {
+		t.Fatal("expected no access token in empty context")
+	}
+}
@@ -0,0 +1,170 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Lambels/autho"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is the twitter oauth2 endpoint.
+var Endpoint oauth2.Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://twitter.com/i/oauth2/authorize",
+	TokenURL: "https://api.twitter.com/2/oauth2/token",
+}
+
+// OfflineAccessScope is the scope to request for the token exchange to return a refresh token.
+const OfflineAccessScope string = "offline.access"
+
+// User represents the twitter api v2 user returned by the twitter.NewOAuth2UserHandler.
+//
+// https://developer.twitter.com/en/docs/twitter-api/data-dictionary/object-model/user
+type User struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Username        string `json:"username"`
+	ProfileImageURL string `json:"profile_image_url"`
+	Verified        bool   `json:"verified"`
+	CreatedAt       string `json:"created_at"`
+	Description     string `json:"description"`
+	Location        string `json:"location"`
+	URL             string `json:"url"`
+	Protected       bool   `json:"protected"`
+}
+
+// Identity implements autho.Identifier, the twitter api v2 doesn't return emails.
+func (u *User) Identity() autho.Identity {
+	return autho.Identity{
+		ID: u.ID,
+	}
+}
+
+// NewOAuth2CallbackHandler is a helper function that constructs
+// a new callback handler using the default token handler twitter.NewOAuth2TokenHandler()
+// wrapped around the default twitter.NewOAuth2UserHandler().
+//
+// This method saves a lot of boilerplate. For more customisable handlers construct your
+// own callback handler by wrapping your own specific token handler around your own specific user handler.
+func NewOAuth2CallbackHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	return NewOAuth2TokenHandler(
+		cfg,
+		ckCfg,
+		errHandler,
+		NewOAuth2UserHandler(
+			cfg,
+			errHandler,
+			terminalHandler,
+			opts...,
+		),
+	)
+}
+
+// NewOAuth2LoginHandler creates a new LoginHandler which is responsible for setting a random
+// value (state) to the state cookie and the PKCE code verifier to the verifier cookie, twitter
+// requires PKCE for all clients. Afterwards the login handler is also responsible for
+// redirecting the user to the provider for the users grant.
+//
+// Add twitter.OfflineAccessScope to cfg.Scopes to obtain a refresh token.
+func NewOAuth2LoginHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig) http.Handler {
+	return autho2.NewPKCELoginHandler(configFor(cfg), ckCfg)
+}
+
+// NewOAuth2TokenHandler creates a new TokenHandler which is the first handler in the chain responding
+// to the callback from the provider, it is responsible for parsing the response for auth code
+// and state then comparing the cookie state with the request state. Following the parsing the
+// TokenHandler performs the token exchange with the PKCE code verifier and adds the token to the
+// request context, calling on success the UserHandler.
+//
+// Confidential clients (cfg.ClientSecret set) authenticate the token exchange with http basic
+// auth, public clients send their client id as a parameter.
+func NewOAuth2TokenHandler(cfg *oauth2.Config, ckCfg *autho.CookieConfig, errHandler, userHandler http.Handler) http.Handler {
+	return autho2.NewPKCETokenHandler(configFor(cfg), ckCfg, errHandler, userHandler)
+}
+
+// NewOAuth2UserHandler creates a new twitter UserHandler responsible for using the tokens provided
+// by the TokenHandler in exchange for the users resource from the /2/users/me endpoint. The user
+// resource is set under the request context.
+//
+//	user, ok := autho.UserFromContext(r.Context()).(*twitter.User)
+//
+// The UserModel used by default by the twitter.NewOAuth2UserHandler is: https://developer.twitter.com/en/docs/twitter-api/data-dictionary/object-model/user
+func NewOAuth2UserHandler(cfg *oauth2.Config, errHandler, terminalHandler http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	fetch := func(ctx context.Context, client *http.Client) (*User, error) {
+		return usersMe(ctx, client, o)
+	}
+
+	return autho2.NewUserHandlerFunc(configFor(cfg), fetch, errHandler, terminalHandler)
+}
+
+// configFor sets the token endpoint auth style of cfg following the client type.
+func configFor(cfg *oauth2.Config) *oauth2.Config {
+	if cfg.Endpoint.AuthStyle != oauth2.AuthStyleAutoDetect {
+		return cfg
+	}
+
+	c := *cfg
+	if c.ClientSecret != "" {
+		c.Endpoint.AuthStyle = oauth2.AuthStyleInHeader
+	} else {
+		c.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+	return &c
+}
+
+// apiError represents an error returned by the twitter api v2.
+type apiError struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("autho: twitter api error (%d): %s %s", e.Status, e.Title, e.Detail)
+}
+
+// apiURL is the base url of the twitter api v2.
+const apiURL string = "https://api.twitter.com/2"
+
+func usersMe(ctx context.Context, client *http.Client, o *options) (*User, error) {
+	params := url.Values{}
+	if len(o.userFields) > 0 {
+		params.Set("user.fields", strings.Join(o.userFields, ","))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/users/me?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &apiError{Status: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(apiErr)
+		return nil, apiErr
+	}
+
+	var body struct {
+		Data *User `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Data == nil || body.Data.ID == "" {
+		return nil, autho.ErrNoUser
+	}
+
+	return body.Data, nil
+}
@@ -0,0 +1,108 @@
+package twitter
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Lambels/autho"
+	"github.com/Lambels/autho/internal/apitest"
+	autho2 "github.com/Lambels/autho/oauth2"
+	"golang.org/x/oauth2"
+)
+
+func TestOAuth2Flow(t *testing.T) {
+	var gotVerifier string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client-id" || secret != "client-secret" {
+			t.Errorf("expected basic auth client credentials")
+		}
+		gotVerifier = r.PostFormValue("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"token_type":    "bearer",
+			"expires_in":    7200,
+		})
+	})
+	mux.HandleFunc("/2/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			t.Errorf("unexpected authorization: %s", r.Header.Get("Authorization"))
+		}
+		if got := r.URL.Query().Get("user.fields"); got != "username,profile_image_url" {
+			t.Errorf("unexpected user.fields: %s", got)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":                "2244994945",
+				"name":              "Jane Doe",
+				"username":          "jane",
+				"profile_image_url": "https://pbs.example.com/jane.jpg",
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Scopes:       []string{"users.read", OfflineAccessScope},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  srv.URL + "/oauth2/authorize",
+			TokenURL: srv.URL + "/oauth2/token",
+		},
+	}
+	ckCfg := autho.NewDebugCookieConfig("state")
+
+	// login phase.
+	w := httptest.NewRecorder()
+	NewOAuth2LoginHandler(cfg, ckCfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	var verifier string
+	for _, ck := range cookies {
+		if ck.Name == "state_verifier" {
+			verifier = ck.Value
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if loc.Query().Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) || loc.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected S256 code challenge of the verifier cookie but got %s", loc.RawQuery)
+	}
+
+	// callback phase.
+	var gotUser *User
+	var gotToken *oauth2.Token
+	terminal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, _ = autho.UserFromContext(r.Context()).(*User)
+		gotToken, _ = autho2.TokenFromContext(r.Context())
+	})
+	h := NewOAuth2CallbackHandler(cfg, ckCfg, nil, terminal, WithUserFields("username", "profile_image_url"))
+
+	r := httptest.NewRequest(http.MethodGet, "/callback?code=auth-code&state="+loc.Query().Get("state"), nil)
+	for _, ck := range cookies {
+		r.AddCookie(ck)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, apitest.Request(r, srv))
+
+	if gotVerifier != verifier {
+		t.Fatalf("expected code verifier %s but got %s", verifier, gotVerifier)
+	}
+	if gotUser == nil || gotUser.ID != "2244994945" || gotUser.Username != "jane" {
+		t.Fatalf("unexpected user: %+v (%d: %s)", gotUser, w.Code, w.Body.String())
+	}
+	if gotToken.RefreshToken != "refresh-token" {
+		t.Fatalf("expected refresh token but got %+v", gotToken)
+	}
+}
@@ -0,0 +1,49 @@
+package twitter
+
+// Option configures the twitter handlers.
+type Option func(*options)
+
+// DefaultUserFields are the user.fields requested by default by the twitter.NewOAuth2UserHandler,
+// the id, name and username fields are always returned.
+var DefaultUserFields []string = []string{"profile_image_url", "verified", "created_at", "description"}
+
+type options struct {
+	email      bool
+	userFields []string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		userFields: DefaultUserFields,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithEmail requests the email of the user from the twitter api, the email is only returned to
+// apps approved for the "Request email address from users" permission.
+//
+// Only used by the oauth1 user handler.
+//
+// https://developer.twitter.com/en/docs/twitter-api/v1/accounts-and-users/manage-account-settings/api-reference/get-account-verify_credentials
+func WithEmail() Option {
+	return func(o *options) {
+		o.email = true
+	}
+}
+
+// WithUserFields sets the user.fields requested from the /2/users/me endpoint.
+//
+// Only used by the oauth2 user handler.
+//
+// https://developer.twitter.com/en/docs/twitter-api/data-dictionary/object-model/user
+//
+// Default: twitter.DefaultUserFields
+func WithUserFields(fields ...string) Option {
+	return func(o *options) {
+		o.userFields = fields
+	}
+}